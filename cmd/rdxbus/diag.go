@@ -0,0 +1,197 @@
+// cmd/rdxbus/diag.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/worker"
+)
+
+// diagStep is one request issued by the diag command.
+type diagStep struct {
+	fc   uint8
+	sub  uint16
+	data uint16
+}
+
+// queryPattern is sent with Return Query Data and must come back unchanged.
+const queryPattern = 0xA537
+
+var diagSteps = []diagStep{
+	{fc: 7},
+	{fc: 8, sub: format.DiagReturnQueryData, data: queryPattern},
+	{fc: 8, sub: format.DiagReturnDiagRegister},
+	{fc: 8, sub: format.DiagBusMessageCount},
+	{fc: 8, sub: format.DiagBusCommErrorCount},
+	{fc: 8, sub: format.DiagBusExceptionCount},
+	{fc: 8, sub: format.DiagServerMessageCount},
+	{fc: 8, sub: format.DiagServerNoResponseCount},
+	{fc: 8, sub: format.DiagServerNAKCount},
+	{fc: 8, sub: format.DiagServerBusyCount},
+	{fc: 8, sub: format.DiagBusCharOverrunCount},
+	{fc: 11},
+	{fc: 12},
+	{fc: 17},
+}
+
+// runDiag runs every serial-line diagnostic against one unit and
+// tabulates the results. Unsupported diagnostics show their exception.
+func runDiag(cfg *config.Config) {
+	eng := newEngine(cfg)
+	defer closeEngine(eng)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	steps := diagSteps
+	if cfg.ClearCounters {
+		steps = append(steps[:len(steps):len(steps)], diagStep{fc: 8, sub: format.DiagClearCounters})
+	}
+
+	start := time.Now()
+	rows := make([]output.Row, 0, len(steps))
+	for _, st := range steps {
+		req := requestFromConfig(cfg)
+		req.FunctionCode = st.fc
+		req.SubFunction = st.sub
+		req.Value = st.data
+
+		res := worker.Execute(ctx, eng, req)
+		if engine.IsCanceled(res.EngineResult.Err) {
+			break
+		}
+		rows = append(rows, diagRow(req, res.EngineResult))
+	}
+
+	emit(os.Stdout, cfg, output.Output{
+		Meta: withTLS(output.Meta{
+			Mode:      "diag",
+			Target:    targetLabel(cfg),
+			UnitID:    cfg.UnitID,
+			Timestamp: start,
+		}, eng),
+		Table: buildDiagTable(rows),
+	})
+}
+
+// isDiag reports whether fc is a serial-line diagnostic function code.
+func isDiag(fc uint8) bool {
+	switch fc {
+	case 7, 8, 11, 12, 17:
+		return true
+	}
+	return false
+}
+
+func diagRow(req engine.Request, res engine.Result) output.Row {
+	value := ""
+	if res.Err != nil {
+		value = "error: " + res.Err.Error()
+	} else if v, err := diagValue(req, res.Raw); err != nil {
+		value = "decode error: " + err.Error()
+	} else {
+		value = v
+	}
+
+	return output.Row{
+		Cells: map[string]any{
+			"fc":      req.FunctionCode,
+			"item":    diagItem(req),
+			"value":   value,
+			"latency": res.Duration,
+		},
+	}
+}
+
+func diagItem(req engine.Request) string {
+	switch req.FunctionCode {
+	case 7:
+		return "Read Exception Status"
+	case 8:
+		return format.DiagName(req.SubFunction)
+	case 11:
+		return "Get Comm Event Counter"
+	case 12:
+		return "Get Comm Event Log"
+	case 17:
+		return "Report Server ID"
+	}
+	return fmt.Sprintf("FC %d", req.FunctionCode)
+}
+
+// diagValue decodes a diagnostic response for display.
+func diagValue(req engine.Request, pdu []byte) (string, error) {
+	switch req.FunctionCode {
+	case 7:
+		s, err := format.DecodeExceptionStatus(pdu)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("0x%02X (%08b)", s, s), nil
+
+	case 8:
+		_, data, err := format.DecodeDiagnostics(pdu)
+		if err != nil {
+			return "", err
+		}
+		switch req.SubFunction {
+		case format.DiagReturnQueryData:
+			if data != req.Value {
+				return "", fmt.Errorf("query data 0x%04X returned as 0x%04X", req.Value, data)
+			}
+			return fmt.Sprintf("echoed 0x%04X", data), nil
+		case format.DiagReturnDiagRegister:
+			return fmt.Sprintf("0x%04X", data), nil
+		case format.DiagClearCounters:
+			return "cleared", nil
+		}
+		return fmt.Sprintf("%d", data), nil
+
+	case 11:
+		c, err := format.DecodeCommEventCounter(pdu)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("status=0x%04X events=%d", c.Status, c.EventCount), nil
+
+	case 12:
+		l, err := format.DecodeCommEventLog(pdu)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("status=0x%04X events=%d messages=%d log=[% X]", l.Status, l.EventCount, l.MessageCount, l.Events), nil
+
+	case 17:
+		id, err := format.DecodeServerID(pdu)
+		if err != nil {
+			return "", err
+		}
+		run := "OFF"
+		if id.Running {
+			run = "ON"
+		}
+		return fmt.Sprintf("id=0x%02X run=%s data=[% X]", id.ID, run, id.Additional), nil
+	}
+	return "", fmt.Errorf("unsupported function code: %d", req.FunctionCode)
+}
+
+func buildDiagTable(rows []output.Row) *output.Table {
+	return &output.Table{
+		Columns: []output.Column{
+			{Key: "fc", Title: "FC"},
+			{Key: "item", Title: "Diagnostic"},
+			{Key: "value", Title: "Value"},
+			{Key: "latency", Title: "Latency"},
+		},
+		Rows: rows,
+	}
+}
@@ -3,13 +3,20 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 )
 
 func runEasy() {
 	reader := bufio.NewReader(os.Stdin)
 
+	// Ctrl+C cancels in-flight requests instead of waiting for timeouts.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	fmt.Println("RDXBus Easy Mode")
 	fmt.Println("----------------")
 
@@ -20,14 +27,17 @@ func runEasy() {
 	fmt.Println("  1) Read once")
 	fmt.Println("  2) Poll continuously")
 	fmt.Println("  3) Scan helpers")
+	fmt.Println("  4) Write")
 
 	switch promptInt(reader, "Selection", 1) {
 	case 1:
-		easyReadOnce(reader, target, unitID)
+		easyReadOnce(ctx, reader, target, unitID)
 	case 2:
-		easyPoll(reader, target, unitID)
+		easyPoll(ctx, reader, target, unitID)
 	case 3:
-		runEasyScan(reader)
+		runEasyScan(ctx, reader)
+	case 4:
+		easyWrite(ctx, reader, target, unitID)
 	default:
 		fmt.Println("Invalid selection")
 	}
@@ -1,7 +1,75 @@
 // cmd/rdxbus/easy_helpers.go
 package main
 
-import "github.com/tamzrod/rdxbus/internal/output"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/worker"
+)
+
+// easyEngine returns the engine for an Easy Mode target; see
+// easyConfig. It is built like the expert CLI's, so serial lines and
+// UDP sockets stay open between requests.
+func easyEngine(target string) (engine.Engine, error) {
+	cfg, err := easyConfig(target)
+	if err != nil {
+		return nil, err
+	}
+	return newEngine(cfg), nil
+}
+
+// easyConfig maps an Easy Mode target to the expert transport flags: a
+// TCP address, udp://host:port for MBAP datagrams, rtu://host:port or
+// ascii://host:port for frames tunneled over TCP, or a serial device
+// such as /dev/ttyUSB0@9600,8N1 spoken as RTU (or as ASCII with an
+// ascii:// prefix, 7E1 unless the line settings are given).
+func easyConfig(target string) (*config.Config, error) {
+	cfg := &config.Config{
+		TargetAddr:          target,
+		Transport:           "tcp",
+		ConnMode:            "dial",
+		Retries:             client.DefaultUDPRetries,
+		ReconnectBackoff:    100 * time.Millisecond,
+		ReconnectMaxBackoff: 5 * time.Second,
+	}
+
+	if addr := strings.TrimPrefix(target, "udp://"); addr != target {
+		cfg.TargetAddr, cfg.Transport, cfg.ConnMode = addr, "udp", "persistent"
+		return cfg, nil
+	}
+	if addr := strings.TrimPrefix(target, "rtu://"); addr != target {
+		cfg.TargetAddr, cfg.Transport = addr, "rtu-over-tcp"
+		return cfg, nil
+	}
+
+	ascii := false
+	if addr := strings.TrimPrefix(target, "ascii://"); addr != target {
+		ascii, target = true, addr
+		cfg.TargetAddr, cfg.Transport = addr, "ascii-over-tcp"
+	}
+
+	serial, ok, err := config.ParseSerialTarget(target)
+	if err != nil || !ok {
+		return cfg, err
+	}
+	cfg.Transport, cfg.ConnMode = "rtu", "persistent"
+	if ascii {
+		cfg.Transport = "ascii"
+		if !strings.Contains(target, ",") {
+			serial.DataBits = 7
+		}
+	}
+	cfg.Serial = serial
+	return cfg, nil
+}
 
 func buildRows(start uint16, values []uint16) []output.Row {
 	rows := make([]output.Row, 0, len(values))
@@ -25,3 +93,200 @@ func buildTable(rows []output.Row) *output.Table {
 		Rows: rows,
 	}
 }
+
+// decoding is how read registers are shown: the type, its byte order,
+// or all four orders side by side when auto is set.
+type decoding struct {
+	typ   format.Type
+	order format.Order
+	auto  bool
+	bits  []format.BitName
+
+	// scaling turns values into engineering values; with hasScaleReg,
+	// scaleReg is a SunSpec scale register whose exponent resolveScale
+	// stores in exp.
+	scaling     format.Scaling
+	scaleReg    uint16
+	hasScaleReg bool
+	exp         int
+}
+
+// scaled reports whether values get an engineering column.
+func (d decoding) scaled() bool {
+	return !d.scaling.IsZero() || d.hasScaleReg
+}
+
+// resolveScale reads the scale register into d.exp: from values when
+// the register is among those read for req, otherwise with a separate
+// one-register read of the same table.
+func (d *decoding) resolveScale(ctx context.Context, eng engine.Engine, req engine.Request, values []uint16) error {
+	if !d.hasScaleReg {
+		return nil
+	}
+
+	var reg uint16
+	if i := int(d.scaleReg) - int(req.Address); i >= 0 && i < len(values) {
+		reg = values[i]
+	} else {
+		fc := uint8(3)
+		if req.FunctionCode == 4 {
+			fc = 4
+		}
+		res := worker.Execute(ctx, eng, engine.Request{
+			UnitID:       req.UnitID,
+			FunctionCode: fc,
+			Address:      d.scaleReg,
+			Quantity:     1,
+			Timeout:      req.Timeout,
+		})
+		if res.EngineResult.Err != nil {
+			return fmt.Errorf("scale register %d: %w", d.scaleReg, res.EngineResult.Err)
+		}
+		regs, err := format.DecodeReadValues(res.EngineResult.Raw, fc, 1)
+		if err != nil {
+			return fmt.Errorf("scale register %d: %w", d.scaleReg, err)
+		}
+		reg = regs[0]
+	}
+
+	exp, err := format.ScaleExponent(reg)
+	if err != nil {
+		return fmt.Errorf("scale register %d: %w", d.scaleReg, err)
+	}
+	d.exp = exp
+	return nil
+}
+
+// table shows registers read from start, with an optional message.
+func (d decoding) table(start uint16, values []uint16) (*output.Table, string, error) {
+	if d.auto {
+		return orderTable(start, values, d.typ)
+	}
+	if d.typ == format.TypeBitfield && len(d.bits) > 0 {
+		return bitTable(start, values, d.bits), "", nil
+	}
+	t, err := d.readTable(start, values)
+	return t, "", err
+}
+
+// readTable shows registers read from start: raw words only for plain
+// uint16, or each value of the type in byte order next to the words it
+// came from, plus its engineering value when scaled.
+func (d decoding) readTable(start uint16, values []uint16) (*output.Table, error) {
+	typ, order := d.typ, d.order
+	if typ == format.TypeUint16 && !d.scaled() {
+		return buildTable(buildRows(start, values)), nil
+	}
+
+	decoded, err := format.DecodeTyped(start, values, typ, order)
+	if err != nil {
+		return nil, err
+	}
+
+	title := typ.String()
+	if typ.Registers() > 1 || typ == format.TypeString {
+		title += " " + order.String()
+	}
+
+	rows := make([]output.Row, 0, len(decoded))
+	for _, v := range decoded {
+		cells := map[string]any{
+			"address": int(v.Address),
+			"raw":     rawWords(v.Raw),
+			"value":   v.Value,
+		}
+		if d.scaled() {
+			eng, err := d.scaling.Apply(v.Value, d.exp)
+			if err != nil {
+				return nil, err
+			}
+			cells["scaled"] = eng
+		}
+		rows = append(rows, output.Row{Cells: cells})
+	}
+
+	columns := []output.Column{
+		{Key: "address", Title: "Address"},
+		{Key: "raw", Title: "Raw"},
+		{Key: "value", Title: "Value (" + title + ")"},
+	}
+	if d.scaled() {
+		columns = append(columns, output.Column{Key: "scaled", Title: "Scaled"})
+	}
+	return &output.Table{Columns: columns, Rows: rows}, nil
+}
+
+// bitTable shows one column per named bit, 1 when the bit is set. The
+// register word is kept as the row's value for JSON and metrics.
+func bitTable(start uint16, values []uint16, bits []format.BitName) *output.Table {
+	columns := []output.Column{
+		{Key: "address", Title: "Address"},
+		{Key: "raw", Title: "Raw"},
+	}
+	for _, b := range bits {
+		columns = append(columns, output.Column{Key: b.Name, Title: b.Name})
+	}
+
+	rows := make([]output.Row, 0, len(values))
+	for i, v := range values {
+		cells := map[string]any{
+			"address": int(start) + i,
+			"raw":     format.Bits(v).String(),
+			"value":   format.Bits(v),
+		}
+		for _, b := range bits {
+			if format.Bits(v).Bit(b.Bit) {
+				cells[b.Name] = 1
+			} else {
+				cells[b.Name] = 0
+			}
+		}
+		rows = append(rows, output.Row{Cells: cells})
+	}
+	return &output.Table{Columns: columns, Rows: rows}
+}
+
+// orderTable shows each value of typ under all four byte orders side
+// by side. The message names the order that looks most plausible.
+func orderTable(start uint16, values []uint16, typ format.Type) (*output.Table, string, error) {
+	all, err := format.DecodeAllOrders(start, values, typ)
+	if err != nil {
+		return nil, "", err
+	}
+	guess, err := format.GuessOrder(values, typ)
+	if err != nil {
+		return nil, "", err
+	}
+
+	columns := []output.Column{
+		{Key: "address", Title: "Address"},
+		{Key: "raw", Title: "Raw"},
+	}
+	for _, o := range format.Orders() {
+		columns = append(columns, output.Column{Key: o.String(), Title: o.String()})
+	}
+
+	rows := make([]output.Row, 0, len(all[0]))
+	for i, v := range all[0] {
+		cells := map[string]any{
+			"address": int(v.Address),
+			"raw":     rawWords(v.Raw),
+		}
+		for _, o := range format.Orders() {
+			cells[o.String()] = all[o][i].Value
+		}
+		rows = append(rows, output.Row{Cells: cells})
+	}
+
+	msg := fmt.Sprintf("%s under each byte order; most plausible: %s\n", typ, guess)
+	return &output.Table{Columns: columns, Rows: rows}, msg, nil
+}
+
+// rawWords formats registers as hex words, e.g. "0x4366 0x199A".
+func rawWords(words []uint16) string {
+	parts := make([]string, len(words))
+	for i, w := range words {
+		parts[i] = fmt.Sprintf("0x%04X", w)
+	}
+	return strings.Join(parts, " ")
+}
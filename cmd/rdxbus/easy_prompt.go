@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/format"
 )
 
 func prompt(reader *bufio.Reader, label, def string) string {
@@ -28,3 +32,163 @@ func promptInt(reader *bufio.Reader, label string, def int) int {
 		fmt.Println("Invalid number")
 	}
 }
+
+// promptRange asks for an integer in min..max until one is given.
+func promptRange(reader *bufio.Reader, label string, def, min, max int) int {
+	for {
+		n := promptInt(reader, label, def)
+		if n >= min && n <= max {
+			return n
+		}
+		fmt.Printf("Must be %d..%d\n", min, max)
+	}
+}
+
+// promptWord asks for exactly one 16-bit value (decimal, 0x hex or 0b
+// binary).
+func promptWord(reader *bufio.Reader, label, def string) uint16 {
+	for {
+		values, err := config.ParseValues(prompt(reader, label, def))
+		if err == nil && len(values) == 1 {
+			return values[0]
+		}
+		fmt.Println("Enter one value 0..65535")
+	}
+}
+
+func promptValues(reader *bufio.Reader, label, def string) []uint16 {
+	for {
+		v := prompt(reader, label, def)
+		values, err := config.ParseValues(v)
+		if err == nil && len(values) > 0 {
+			return values
+		}
+		fmt.Println("Invalid values")
+	}
+}
+
+// promptCSV asks whether poll samples are logged to a CSV file, and
+// how it rotates, into cfg.
+func promptCSV(reader *bufio.Reader, cfg *config.Config) {
+	path := prompt(reader, "CSV log file (none = no log)", "none")
+	if strings.EqualFold(path, "none") {
+		return
+	}
+	cfg.CSVFile = path
+
+	if mb := promptInt(reader, "Rotate at size in MB (0 = never)", 0); mb > 0 {
+		cfg.CSVMaxSize = int64(mb) << 20
+	}
+	if min := promptInt(reader, "Rotate every N minutes (0 = never)", 0); min > 0 {
+		cfg.CSVMaxAge = time.Duration(min) * time.Minute
+	}
+	if cfg.CSVMaxSize > 0 || cfg.CSVMaxAge > 0 {
+		cfg.CSVGzip = strings.EqualFold(prompt(reader, "Gzip rotated files (y/n)", "y"), "y")
+	}
+	if !strings.EqualFold(prompt(reader, "Show table too (y/n)", "y"), "y") {
+		cfg.Output = "none"
+	}
+}
+
+// promptDecoding asks how registers read with fc are shown.
+func promptDecoding(reader *bufio.Reader, fc int) decoding {
+	var d decoding
+	if fc != 3 && fc != 4 {
+		return d
+	}
+	d.typ = promptType(reader)
+	switch {
+	case d.typ == format.TypeString:
+		d.order = promptSwap(reader)
+	case d.typ == format.TypeBitfield:
+		d.bits = promptBits(reader)
+	case d.typ.Registers() > 1:
+		d.order, d.auto = promptOrder(reader)
+	}
+	if !d.auto && (d.typ.Numeric() || d.typ == format.TypeBCD16 || d.typ == format.TypeBCD32) {
+		promptScaling(reader, &d)
+	}
+	return d
+}
+
+// promptScaling asks for an optional scale factor, offset, unit and
+// SunSpec scale register.
+func promptScaling(reader *bufio.Reader, d *decoding) {
+	if !strings.EqualFold(prompt(reader, "Apply scaling (y/n)", "n"), "y") {
+		return
+	}
+	d.scaling.Scale = promptFloat(reader, "Scale factor", 1)
+	d.scaling.Offset = promptFloat(reader, "Offset", 0)
+	if unit := prompt(reader, "Unit (e.g. V, kWh)", "none"); !strings.EqualFold(unit, "none") {
+		d.scaling.Unit = unit
+	}
+	if reg := promptInt(reader, "Scale register address (-1 = none)", -1); reg >= 0 && reg <= 0xFFFF {
+		d.scaleReg, d.hasScaleReg = uint16(reg), true
+	}
+}
+
+func promptFloat(reader *bufio.Reader, label string, def float64) float64 {
+	for {
+		v := prompt(reader, label, strconv.FormatFloat(def, 'g', -1, 64))
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return f
+		}
+		fmt.Println("Invalid number")
+	}
+}
+
+// promptSwap asks whether string bytes are swapped within each register.
+func promptSwap(reader *bufio.Reader) format.Order {
+	for {
+		v := prompt(reader, "Byte order (ABCD, BADC = byte-swapped)", format.OrderABCD.String())
+		o, err := format.ParseOrder(v)
+		if err == nil && (o == format.OrderABCD || o == format.OrderBADC) {
+			return o
+		}
+		fmt.Println("Invalid byte order")
+	}
+}
+
+// promptBits asks for optional bit names; "none" shows the bit pattern.
+func promptBits(reader *bufio.Reader) []format.BitName {
+	for {
+		v := prompt(reader, "Bit names (e.g. 0:running,1:fault)", "none")
+		if strings.EqualFold(v, "none") {
+			return nil
+		}
+		bits, err := format.ParseBitLayout(v)
+		if err == nil {
+			return bits
+		}
+		fmt.Println(err)
+	}
+}
+
+// promptType asks how read registers are interpreted.
+func promptType(reader *bufio.Reader) format.Type {
+	label := "Data type (" + strings.Join(format.TypeNames(), ", ") + ")"
+	for {
+		t, err := format.ParseType(prompt(reader, label, format.TypeUint16.String()))
+		if err == nil {
+			return t
+		}
+		fmt.Println("Invalid type")
+	}
+}
+
+// promptOrder asks for the byte order of a multi-register type;
+// auto is true when all four orders should be compared.
+func promptOrder(reader *bufio.Reader) (order format.Order, auto bool) {
+	for {
+		v := prompt(reader, "Byte order (ABCD, CDAB, BADC, DCBA, auto)", format.OrderABCD.String())
+		if strings.EqualFold(v, "auto") {
+			return format.OrderABCD, true
+		}
+		o, err := format.ParseOrder(v)
+		if err == nil {
+			return o, false
+		}
+		fmt.Println("Invalid byte order")
+	}
+}
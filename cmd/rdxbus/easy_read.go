@@ -7,20 +7,26 @@ import (
 	"os"
 	"time"
 
+	"github.com/tamzrod/rdxbus/internal/config"
 	"github.com/tamzrod/rdxbus/internal/engine"
 	"github.com/tamzrod/rdxbus/internal/format"
 	"github.com/tamzrod/rdxbus/internal/output"
 	"github.com/tamzrod/rdxbus/internal/render"
-	"github.com/tamzrod/rdxbus/internal/scheduler"
 	"github.com/tamzrod/rdxbus/internal/worker"
 )
 
-func easyReadOnce(reader *bufio.Reader, target string, unitID int) {
+func easyReadOnce(ctx context.Context, reader *bufio.Reader, target string, unitID int) {
 	fc := promptInt(reader, "Function code (1–4)", 3)
 	addr := promptInt(reader, "Start address", 0)
 	qty := promptInt(reader, "Quantity", 10)
+	dec := promptDecoding(reader, fc)
 
-	eng := &engine.ModbusEngine{TargetAddr: target}
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
 
 	req := engine.Request{
 		UnitID:       uint8(unitID),
@@ -30,7 +36,7 @@ func easyReadOnce(reader *bufio.Reader, target string, unitID int) {
 		Timeout:      2 * time.Second,
 	}
 
-	res := worker.Execute(context.Background(), eng, req)
+	res := worker.Execute(ctx, eng, req)
 	if res.EngineResult.Err != nil {
 		render.Render(os.Stdout, output.Output{Error: res.EngineResult.Err.Error()})
 		return
@@ -46,6 +52,16 @@ func easyReadOnce(reader *bufio.Reader, target string, unitID int) {
 		return
 	}
 
+	if err := dec.resolveScale(ctx, eng, req, values); err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	table, msg, err := dec.table(req.Address, values)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+
 	render.Render(os.Stdout, output.Output{
 		Meta: output.Meta{
 			Mode:     "read",
@@ -54,17 +70,33 @@ func easyReadOnce(reader *bufio.Reader, target string, unitID int) {
 			Function: uint8(fc),
 			Latency:  res.EngineResult.Duration,
 		},
-		Table: buildTable(buildRows(req.Address, values)),
+		Message: msg,
+		Table:   table,
 	})
 }
 
-func easyPoll(reader *bufio.Reader, target string, unitID int) {
+// easyPoll reads the same registers every interval until Ctrl+C,
+// optionally logging each sample to a CSV file. Failed samples are
+// shown and polling goes on.
+func easyPoll(ctx context.Context, reader *bufio.Reader, target string, unitID int) {
 	fc := promptInt(reader, "Function code (1–4)", 3)
 	addr := promptInt(reader, "Start address", 0)
 	qty := promptInt(reader, "Quantity", 10)
+	dec := promptDecoding(reader, fc)
 	intervalMs := promptInt(reader, "Poll interval (ms)", 1000)
 
-	eng := &engine.ModbusEngine{TargetAddr: target}
+	cfg := &config.Config{
+		Output: "table",
+		Poll:   time.Duration(intervalMs) * time.Millisecond,
+	}
+	promptCSV(reader, cfg)
+
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
 
 	req := engine.Request{
 		UnitID:       uint8(unitID),
@@ -74,32 +106,43 @@ func easyPoll(reader *bufio.Reader, target string, unitID int) {
 		Timeout:      2 * time.Second,
 	}
 
-	policy := &scheduler.Interval{
-		Every: time.Duration(intervalMs) * time.Millisecond,
-	}
+	err = poll(ctx, cfg, nil, func(ctx context.Context) output.Output {
+		out := output.Output{
+			Meta: output.Meta{
+				Mode:      "poll",
+				Target:    target,
+				UnitID:    uint8(unitID),
+				Function:  uint8(fc),
+				Address:   req.Address,
+				Quantity:  req.Quantity,
+				Timestamp: time.Now(),
+			},
+		}
 
-	for range policy.Run(context.Background()) {
-		res := worker.Execute(context.Background(), eng, req)
+		res := worker.Execute(ctx, eng, req)
+		out.Meta.Latency = res.EngineResult.Duration
 		if res.EngineResult.Err != nil {
-			render.Render(os.Stdout, output.Output{Error: res.EngineResult.Err.Error()})
-			return
+			return withError(out, res.EngineResult.Err)
 		}
 
-		values, _ := format.DecodeReadValues(
+		values, err := format.DecodeReadValues(
 			res.EngineResult.Raw,
 			req.FunctionCode,
 			req.Quantity,
 		)
-
-		render.Render(os.Stdout, output.Output{
-			Meta: output.Meta{
-				Mode:     "poll",
-				Target:   target,
-				UnitID:   uint8(unitID),
-				Function: uint8(fc),
-				Latency:  res.EngineResult.Duration,
-			},
-			Table: buildTable(buildRows(req.Address, values)),
-		})
+		if err != nil {
+			return withError(out, err)
+		}
+		if err := dec.resolveScale(ctx, eng, req, values); err != nil {
+			return withError(out, err)
+		}
+		out.Table, out.Message, err = dec.table(req.Address, values)
+		if err != nil {
+			return withError(out, err)
+		}
+		return out
+	})
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
 	}
 }
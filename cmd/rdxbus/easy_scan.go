@@ -5,32 +5,43 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/render"
 	"github.com/tamzrod/rdxbus/internal/scan"
 )
 
-func runEasyScan(reader *bufio.Reader) {
+func runEasyScan(ctx context.Context, reader *bufio.Reader) {
 	fmt.Println("\nScan helpers")
 	fmt.Println("------------")
 	fmt.Println("  1) Find Unit ID")
 	fmt.Println("  2) Scan address range")
+	fmt.Println("  3) Identify device")
 
 	switch promptInt(reader, "Selection", 1) {
 	case 1:
-		easyScanUnitID(reader)
+		easyScanUnitID(ctx, reader)
 	case 2:
-		easyScanAddress(reader)
+		easyScanAddress(ctx, reader)
+	case 3:
+		easyIdentify(ctx, reader)
 	default:
 		fmt.Println("Invalid selection")
 	}
 }
 
-func easyScanUnitID(reader *bufio.Reader) {
+func easyScanUnitID(ctx context.Context, reader *bufio.Reader) {
 	target := prompt(reader, "Target address", "127.0.0.1:502")
 
-	eng := &engine.ModbusEngine{TargetAddr: target}
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
 	req := engine.Request{
 		UnitID:       1, // valid baseline
 		FunctionCode: 3,
@@ -42,16 +53,51 @@ func easyScanUnitID(reader *bufio.Reader) {
 	strat := scan.NewUnitIDScan(req, 1, 247, 50)
 
 	fmt.Println("\nScanning for Unit ID...")
-	(&scan.Runner{Engine: eng}).Run(context.Background(), strat)
+	(&scan.Runner{Engine: eng}).Run(ctx, strat)
 
 	fmt.Println("Unit ID scan complete")
+
+	unitID, ok := strat.Found()
+	if !ok {
+		fmt.Println("No Unit ID answered")
+		return
+	}
+
+	fmt.Printf("Unit ID %d answered, identifying device...\n\n", unitID)
+	req.UnitID = unitID
+	render.Render(os.Stdout, identifyDevice(ctx, eng, target, req, 1, 0))
+}
+
+func easyIdentify(ctx context.Context, reader *bufio.Reader) {
+	target := prompt(reader, "Target address", "127.0.0.1:502")
+	unitID := promptInt(reader, "Unit ID", 1)
+	code := promptInt(reader, "Access (1=basic, 2=regular, 3=extended)", 1)
+
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
+	req := engine.Request{
+		UnitID:  uint8(unitID),
+		Timeout: 2 * time.Second,
+	}
+
+	fmt.Println()
+	render.Render(os.Stdout, identifyDevice(ctx, eng, target, req, uint8(code), 0))
 }
 
-func easyScanAddress(reader *bufio.Reader) {
+func easyScanAddress(ctx context.Context, reader *bufio.Reader) {
 	target := prompt(reader, "Target address", "127.0.0.1:502")
 	unitID := promptInt(reader, "Unit ID", 1)
 
-	eng := &engine.ModbusEngine{TargetAddr: target}
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
 	req := engine.Request{
 		UnitID:       uint8(unitID),
 		FunctionCode: 3,
@@ -62,7 +108,7 @@ func easyScanAddress(reader *bufio.Reader) {
 	strat := scan.NewAddressScan(req, 0, 1000, 10)
 
 	fmt.Println("\nScanning addresses...")
-	(&scan.Runner{Engine: eng}).Run(context.Background(), strat)
+	(&scan.Runner{Engine: eng}).Run(ctx, strat)
 
 	fmt.Println("Address scan complete")
 }
@@ -0,0 +1,72 @@
+// cmd/rdxbus/easy_write.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/render"
+	"github.com/tamzrod/rdxbus/internal/worker"
+)
+
+func easyWrite(ctx context.Context, reader *bufio.Reader, target string, unitID int) {
+	var fc int
+	for {
+		fc = promptInt(reader, "Function code (5=coil, 6=register, 15=coils, 16=registers, 22=mask)", 6)
+		if fc == 5 || fc == 6 || fc == 15 || fc == 16 || fc == 22 {
+			break
+		}
+		fmt.Println("Not a write function code")
+	}
+	addr := promptRange(reader, "Address", 0, 0, 0xFFFF)
+
+	req := engine.Request{
+		UnitID:       uint8(unitID),
+		FunctionCode: uint8(fc),
+		Address:      uint16(addr),
+		Timeout:      2 * time.Second,
+	}
+
+	switch fc {
+	case 15, 16:
+		req.Values = promptValues(reader, "Values (comma separated)", "0")
+		req.Quantity = uint16(len(req.Values))
+	case 22:
+		req.AndMask = promptWord(reader, "AND mask", "0xFFFF")
+		req.OrMask = promptWord(reader, "OR mask", "0x0000")
+	case 5:
+		req.Value = uint16(promptRange(reader, "Value (0=OFF, 1=ON)", 0, 0, 1))
+	default:
+		req.Value = uint16(promptRange(reader, "Value", 0, 0, 0xFFFF))
+	}
+
+	eng, err := easyEngine(target)
+	if err != nil {
+		render.Render(os.Stdout, output.Output{Error: err.Error()})
+		return
+	}
+	defer closeEngine(eng)
+
+	res := worker.Execute(ctx, eng, req)
+	if res.EngineResult.Err != nil {
+		render.Render(os.Stdout, output.Output{Error: res.EngineResult.Err.Error()})
+		return
+	}
+
+	render.Render(os.Stdout, output.Output{
+		Meta: output.Meta{
+			Mode:     "write",
+			Target:   target,
+			UnitID:   uint8(unitID),
+			Function: uint8(fc),
+			Address:  req.Address,
+			Latency:  res.EngineResult.Duration,
+		},
+		Message: "write successful",
+	})
+}
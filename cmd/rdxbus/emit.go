@@ -0,0 +1,86 @@
+// cmd/rdxbus/emit.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/datalog"
+	"github.com/tamzrod/rdxbus/internal/metrics"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/render"
+	"github.com/tamzrod/rdxbus/internal/scheduler"
+)
+
+// emit writes out in the -output format.
+func emit(w io.Writer, cfg *config.Config, out output.Output) {
+	var err error
+	switch cfg.Output {
+	case "json":
+		err = render.JSON(w, out)
+	case "ndjson":
+		err = render.NDJSON(w, out)
+	case "none":
+	default:
+		render.Render(w, out)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "output error:", err)
+	}
+}
+
+// withError records err in out, with its exception code when the
+// device answered with a Modbus exception.
+func withError(out output.Output, err error) output.Output {
+	out.Error = err.Error()
+	if me, ok := client.IsModbusException(err); ok {
+		out.Exception = me.Code
+	}
+	return out
+}
+
+// poll takes a sample right away and then every -poll interval until
+// ctx is done, emitting each one, logging it to the -csv file and
+// recording it in reg when not nil. Failed samples are emitted too and
+// polling continues, so a long session survives a flaky link. The only
+// error is failing to open the CSV log.
+func poll(ctx context.Context, cfg *config.Config, reg *metrics.Registry, sample func(context.Context) output.Output) error {
+	var log *datalog.CSVLogger
+	if cfg.CSVFile != "" {
+		rot := datalog.Rotation{MaxSize: cfg.CSVMaxSize, MaxAge: cfg.CSVMaxAge, Gzip: cfg.CSVGzip}
+		l, err := datalog.OpenCSV(cfg.CSVFile, rot)
+		if err != nil {
+			return err
+		}
+		defer l.Close()
+		log = l
+	}
+	if cfg.Output == "none" {
+		fmt.Fprintf(os.Stderr, "logging to %s; Ctrl+C to stop\n", cfg.CSVFile)
+	}
+
+	ticks := (&scheduler.Interval{Every: cfg.Poll}).Run(ctx)
+	for {
+		out := sample(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		emit(os.Stdout, cfg, out)
+		if reg != nil {
+			reg.Record(out)
+		}
+		if log != nil {
+			if err := log.Log(out); err != nil {
+				fmt.Fprintln(os.Stderr, "csv error:", err)
+			}
+		}
+
+		if _, ok := <-ticks; !ok {
+			return nil
+		}
+	}
+}
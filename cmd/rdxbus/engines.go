@@ -0,0 +1,199 @@
+// cmd/rdxbus/engines.go
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+// newEngine wires the engine selected by -conn-mode and -transport.
+func newEngine(cfg *config.Config) engine.Engine {
+	transport := transportFromConfig(cfg)
+
+	switch cfg.ConnMode {
+	case "persistent":
+		return &engine.PersistentEngine{
+			TargetAddr: cfg.TargetAddr,
+			Strict:     cfg.Strict,
+			Transport:  transport,
+			Backoff:    cfg.ReconnectBackoff,
+			MaxBackoff: cfg.ReconnectMaxBackoff,
+		}
+	case "pool":
+		size := cfg.Connections
+		if size == 0 {
+			size = cfg.Workers
+		}
+		return &engine.PoolEngine{
+			TargetAddr: cfg.TargetAddr,
+			Strict:     cfg.Strict,
+			Transport:  transport,
+			Size:       size,
+		}
+	case "pipeline":
+		return &engine.PipelineEngine{
+			TargetAddr: cfg.TargetAddr,
+			Depth:      cfg.PipelineDepth,
+		}
+	default:
+		return &engine.ModbusEngine{
+			TargetAddr: cfg.TargetAddr,
+			Strict:     cfg.Strict,
+			Transport:  transport,
+		}
+	}
+}
+
+// targetLabel names the device for output: the TCP address, or the
+// transport and its serial device or address.
+func targetLabel(cfg *config.Config) string {
+	switch cfg.Transport {
+	case "rtu", "ascii":
+		return cfg.Transport + ":" + cfg.Serial.Device
+	case "tls", "udp", "rtu-over-tcp", "ascii-over-tcp":
+		return cfg.Transport + ":" + cfg.TargetAddr
+	}
+	return cfg.TargetAddr
+}
+
+// transportFromConfig maps -transport and the serial flags to a client transport.
+func transportFromConfig(cfg *config.Config) client.Transport {
+	var t client.Transport
+
+	switch cfg.Transport {
+	case "rtu", "rtu-over-tcp":
+		t.Framing = client.FramingRTU
+	case "ascii", "ascii-over-tcp":
+		t.Framing = client.FramingASCII
+	case "udp":
+		t.UDP = true
+		t.Retries = cfg.Retries
+	case "tls":
+		t.TLS = tlsConfig(cfg)
+	}
+	if cfg.Transport == "rtu" || cfg.Transport == "ascii" {
+		serial := cfg.Serial
+		t.Serial = &serial
+	}
+	return t
+}
+
+// tlsConfig loads the -tls-* certificates. Unreadable files end the
+// run like any other configuration error.
+func tlsConfig(cfg *config.Config) *tls.Config {
+	tc, err := cfg.TLS.Config()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	return tc
+}
+
+// decodingFromConfig maps -type, -byte-order, -bits and the scaling
+// flags to a table decoding.
+func decodingFromConfig(cfg *config.Config) decoding {
+	return decoding{
+		typ:         cfg.Type,
+		order:       cfg.Order,
+		auto:        cfg.GuessOrder,
+		bits:        cfg.Bits,
+		scaling:     cfg.Scaling,
+		scaleReg:    uint16(cfg.ScaleRegister),
+		hasScaleReg: cfg.ScaleRegister >= 0,
+	}
+}
+
+// requestFromConfig builds the engine request described by expert flags.
+func requestFromConfig(cfg *config.Config) engine.Request {
+	return engine.Request{
+		UnitID:       cfg.UnitID,
+		FunctionCode: cfg.FunctionCode,
+		Address:      cfg.Address,
+		Quantity:     cfg.Quantity,
+		Timeout:      cfg.Timeout,
+		Value:        cfg.Value,
+		SubFunction:  cfg.SubFunction,
+		Values:       cfg.Values,
+		WriteAddress: cfg.WriteAddress,
+		AndMask:      cfg.AndMask,
+		OrMask:       cfg.OrMask,
+		Records:      cfg.Records,
+		DeviceIDCode: cfg.DeviceIDCode,
+		ObjectID:     cfg.ObjectID,
+	}
+}
+
+// closeEngine releases engine-held connections, if any.
+func closeEngine(eng engine.Engine) {
+	if c, ok := eng.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// printConnStats prints connection reuse counters for engines that keep sockets.
+func printConnStats(w io.Writer, eng engine.Engine) {
+	cs, ok := eng.(interface{ ConnStats() engine.ConnStats })
+	if !ok {
+		return
+	}
+	s := cs.ConnStats()
+	fmt.Fprintf(w, "\nConnections:\n  dials      %d\n  reuses     %d\n  discarded  %d\n", s.Dials, s.Reuses, s.Discarded)
+}
+
+// printDatagramStats prints UDP loss counters for engines that keep sockets.
+func printDatagramStats(w io.Writer, eng engine.Engine) {
+	cs, ok := eng.(interface{ ConnStats() engine.ConnStats })
+	if !ok {
+		return
+	}
+	s := cs.ConnStats()
+	fmt.Fprintf(w, "\nDatagrams:\n  retransmits  %d\n  late         %d\n  duplicates   %d\n  malformed    %d\n", s.Retransmits, s.Late, s.Duplicates, s.Malformed)
+}
+
+// withTLS adds the engine's latest TLS handshake, if any, to m.
+func withTLS(m output.Meta, eng engine.Engine) output.Meta {
+	if info := tlsInfo(eng); info != nil {
+		m.TLS = info.Version + " " + info.CipherSuite
+		m.PeerSubject = info.Subject
+		m.PeerRole = info.Role
+	}
+	return m
+}
+
+// printTLS prints the engine's latest TLS handshake, if any.
+func printTLS(w io.Writer, eng engine.Engine) {
+	info := tlsInfo(eng)
+	if info == nil {
+		return
+	}
+	fmt.Fprintln(w, "tls:", info.Version, info.CipherSuite)
+	fmt.Fprintln(w, "peer:", info.Subject)
+	if info.Role != "" {
+		fmt.Fprintln(w, "role:", info.Role)
+	}
+}
+
+func tlsInfo(eng engine.Engine) *client.TLSInfo {
+	h, ok := eng.(interface{ TLSInfo() *client.TLSInfo })
+	if !ok {
+		return nil
+	}
+	return h.TLSInfo()
+}
+
+// printPipelineStats prints pipelining counters for the pipeline engine.
+func printPipelineStats(w io.Writer, eng engine.Engine) {
+	ps, ok := eng.(interface{ PipelineStats() engine.PipelineStats })
+	if !ok {
+		return
+	}
+	s := ps.PipelineStats()
+	fmt.Fprintf(w, "\nPipeline:\n  max in flight  %d\n  out of order   %d\n  unmatched      %d\n", s.MaxInFlight, s.OutOfOrder, s.Unmatched)
+}
@@ -0,0 +1,188 @@
+// cmd/rdxbus/expert.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/stats"
+	"github.com/tamzrod/rdxbus/internal/worker"
+)
+
+// runOnce executes a single request from expert flags, or repeats it
+// every -poll interval, and exits non-zero on failure.
+func runOnce(cfg *config.Config) {
+	eng := newEngine(cfg)
+	defer closeEngine(eng)
+
+	req := requestFromConfig(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.Poll > 0 {
+		reg, stopMetrics := startMetrics(cfg, &stats.Counters{}, stats.NewHistogram())
+		defer stopMetrics()
+
+		exec := observed(eng, reg)
+		err := poll(ctx, cfg, reg, func(ctx context.Context) output.Output {
+			out, _ := sample(ctx, cfg, exec, req)
+			return out
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "csv error:", err)
+			closeEngine(eng)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out, values := sample(ctx, cfg, eng, req)
+	if cfg.Output != "table" || !printPlain(out, values, eng) {
+		emit(os.Stdout, cfg, out)
+	}
+	if out.Error != "" {
+		closeEngine(eng)
+		os.Exit(1)
+	}
+}
+
+// sample executes req once and describes the result. Plain register
+// and bit reads also return their values.
+func sample(ctx context.Context, cfg *config.Config, eng engine.Engine, req engine.Request) (output.Output, []uint16) {
+	start := time.Now()
+
+	if req.FunctionCode == 43 {
+		out := identifyDevice(ctx, eng, targetLabel(cfg), req, req.DeviceIDCode, req.ObjectID)
+		out.Meta = withTLS(out.Meta, eng)
+		out.Meta.Timestamp = start
+		return out, nil
+	}
+
+	res := worker.Execute(ctx, eng, req)
+
+	meta := withTLS(output.Meta{
+		Mode:      "read",
+		Target:    targetLabel(cfg),
+		UnitID:    req.UnitID,
+		Function:  req.FunctionCode,
+		Latency:   res.EngineResult.Duration,
+		Timestamp: start,
+	}, eng)
+
+	if isDiag(req.FunctionCode) {
+		meta.Mode = "diag"
+		out := output.Output{
+			Meta:  meta,
+			Table: buildDiagTable([]output.Row{diagRow(req, res.EngineResult)}),
+		}
+		if err := res.EngineResult.Err; err != nil {
+			out = withError(out, err)
+		}
+		return out, nil
+	}
+
+	meta.Address, meta.Quantity = span(req)
+	if isWrite(req.FunctionCode) {
+		meta.Mode = "write"
+	}
+	out := output.Output{Meta: meta}
+
+	if err := res.EngineResult.Err; err != nil {
+		return withError(out, err), nil
+	}
+	if isWrite(req.FunctionCode) {
+		return out, nil
+	}
+
+	if req.FunctionCode == 20 || req.FunctionCode == 24 {
+		table, err := recordTable(req, res.EngineResult.Raw)
+		if err != nil {
+			return withError(out, fmt.Errorf("decode: %w", err)), nil
+		}
+		out.Table = table
+		return out, nil
+	}
+
+	values, err := format.DecodeReadValues(
+		res.EngineResult.Raw,
+		req.FunctionCode,
+		req.Quantity,
+	)
+	if err != nil {
+		return withError(out, fmt.Errorf("decode: %w", err)), nil
+	}
+
+	if dec := decodingFromConfig(cfg); dec.typ != format.TypeUint16 || dec.scaled() {
+		if err := dec.resolveScale(ctx, eng, req, values); err != nil {
+			return withError(out, err), nil
+		}
+		table, msg, err := dec.table(req.Address, values)
+		if err != nil {
+			return withError(out, fmt.Errorf("decode: %w", err)), nil
+		}
+		out.Message, out.Table = msg, table
+		return out, nil
+	}
+
+	out.Table = buildTable(buildRows(req.Address, values))
+	return out, values
+}
+
+// printPlain prints plain reads, writes and their errors in the
+// terse line format of the expert CLI. It reports false for outputs
+// that are rendered as tables instead.
+func printPlain(out output.Output, values []uint16, eng engine.Engine) bool {
+	m := out.Meta
+	switch {
+	case m.Mode != "read" && m.Mode != "write":
+		return false
+	case out.Error != "":
+		fmt.Fprintf(os.Stderr, "%s error: %s\n", m.Mode, out.Error)
+	case m.Mode == "write":
+		fmt.Println("write successful")
+		fmt.Println("latency:", m.Latency)
+		printTLS(os.Stdout, eng)
+	case values != nil:
+		fmt.Println("read successful")
+		fmt.Println("latency:", m.Latency)
+		printTLS(os.Stdout, eng)
+		fmt.Println("values:", values)
+	default:
+		return false
+	}
+	return true
+}
+
+// span is the address range req reads or writes, for output.
+func span(req engine.Request) (address, quantity uint16) {
+	switch req.FunctionCode {
+	case 1, 2, 3, 4, 15, 16, 23:
+		return req.Address, req.Quantity
+	case 5, 6, 22:
+		return req.Address, 1
+	case 24:
+		return req.Address, 0
+	}
+	return 0, 0
+}
+
+// isWrite reports whether fc is a write function code.
+func isWrite(fc uint8) bool {
+	switch fc {
+	case 5, 6, 15, 16, 21, 22:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+// cmd/rdxbus/identify.go
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/scan"
+)
+
+// identifyDevice reads device identification objects (FC 43 / MEI 14)
+// of access code starting at objectID, following continuations.
+func identifyDevice(ctx context.Context, eng engine.Engine, target string, base engine.Request, code, objectID uint8) output.Output {
+	strat := scan.NewDeviceIDScan(base, code, objectID)
+	(&scan.Runner{Engine: eng}).Run(ctx, strat)
+
+	out := output.Output{
+		Meta: output.Meta{
+			Mode:     "identify",
+			Target:   target,
+			UnitID:   base.UnitID,
+			Function: 43,
+		},
+	}
+
+	if strat.Err != nil {
+		return withError(out, strat.Err)
+	}
+	if len(strat.Objects) == 0 {
+		out.Error = "device returned no identification objects"
+		return out
+	}
+
+	out.Message = fmt.Sprintf("Conformity level: 0x%02X\n", strat.Conformity)
+	out.Table = buildDeviceIDTable(strat.Objects)
+	return out
+}
+
+func buildDeviceIDTable(objects []format.DeviceObject) *output.Table {
+	rows := make([]output.Row, 0, len(objects))
+	for _, o := range objects {
+		rows = append(rows, output.Row{
+			Cells: map[string]any{
+				"id":     fmt.Sprintf("0x%02X", o.ID),
+				"object": format.DeviceObjectName(o.ID),
+				"value":  o.Value,
+			},
+		})
+	}
+
+	return &output.Table{
+		Columns: []output.Column{
+			{Key: "id", Title: "ID"},
+			{Key: "object", Title: "Object"},
+			{Key: "value", Title: "Value"},
+		},
+		Rows: rows,
+	}
+}
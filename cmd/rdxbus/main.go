@@ -2,15 +2,10 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"os"
-	"time"
 
 	"github.com/tamzrod/rdxbus/internal/config"
-	"github.com/tamzrod/rdxbus/internal/engine"
-	"github.com/tamzrod/rdxbus/internal/format"
-	"github.com/tamzrod/rdxbus/internal/worker"
 )
 
 func main() {
@@ -20,43 +15,48 @@ func main() {
 		return
 	}
 
-	// Expert CLI (single read, legacy behavior)
-	cfg := config.Parse()
-
-	eng := &engine.ModbusEngine{
-		TargetAddr: cfg.TargetAddr,
-		Strict:     cfg.Strict,
+	// Serial-line diagnostics: same flags as the expert CLI
+	if len(os.Args) > 1 && os.Args[1] == "diag" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+		runDiag(config.Parse())
+		return
 	}
 
-	req := engine.Request{
-		UnitID:       cfg.UnitID,
-		FunctionCode: cfg.FunctionCode,
-		Address:      cfg.Address,
-		Quantity:     cfg.Quantity,
-		Timeout:      cfg.Timeout,
+	// One read, of flags or of register map points
+	if len(os.Args) > 1 && os.Args[1] == "read" {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	cfg := config.Parse()
+	loadValues(cfg)
 
-	res := worker.Execute(ctx, eng, req)
+	// Expert CLI: named points from a register map
+	if cfg.MapFile != "" {
+		runMap(cfg)
+		return
+	}
 
-	if res.EngineResult.Err != nil {
-		fmt.Fprintln(os.Stderr, "read error:", res.EngineResult.Err)
-		os.Exit(1)
+	// Expert CLI: load generation when any stress flag is set
+	if cfg.Stress {
+		runStress(cfg)
+		return
 	}
 
-	values, err := format.DecodeReadValues(
-		res.EngineResult.Raw,
-		req.FunctionCode,
-		req.Quantity,
-	)
+	// Expert CLI: one read or write
+	runOnce(cfg)
+}
+
+// loadValues reads the write values of -values-file into cfg.
+func loadValues(cfg *config.Config) {
+	if cfg.ValuesFile == "" {
+		return
+	}
+	b, err := os.ReadFile(cfg.ValuesFile)
+	if err == nil {
+		err = cfg.SetValues(string(b))
+	}
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "decode error:", err)
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
 		os.Exit(1)
 	}
-
-	fmt.Println("read successful")
-	fmt.Println("latency:", res.EngineResult.Duration)
-	fmt.Println("values:", values)
 }
@@ -0,0 +1,57 @@
+// cmd/rdxbus/metrics.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/metrics"
+	"github.com/tamzrod/rdxbus/internal/stats"
+)
+
+// startMetrics serves c, h and the polled values on -metrics-addr. It
+// returns a nil registry and a no-op stop when metrics are off, and
+// exits when the address cannot be listened on.
+func startMetrics(cfg *config.Config, c *stats.Counters, h *stats.Histogram) (*metrics.Registry, func()) {
+	if cfg.MetricsAddr == "" {
+		return nil, func() {}
+	}
+
+	reg := metrics.NewRegistry(c, h)
+	srv, err := metrics.Listen(cfg.MetricsAddr, reg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "metrics error:", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "metrics: http://%s/metrics\n", srv.Addr())
+	return reg, func() { srv.Close() }
+}
+
+// observedEngine classifies every request into the registry's counters
+// and latency histogram, including the planner's batches and scale
+// register reads.
+type observedEngine struct {
+	engine.Engine
+	reg *metrics.Registry
+}
+
+// observed wraps eng for reg, or returns it as is when reg is nil.
+func observed(eng engine.Engine, reg *metrics.Registry) engine.Engine {
+	if reg == nil {
+		return eng
+	}
+	return observedEngine{Engine: eng, reg: reg}
+}
+
+func (o observedEngine) Execute(ctx context.Context, req engine.Request) engine.Result {
+	res := o.Engine.Execute(ctx, req)
+	observe(o.reg.Counters, o.reg.Latency, res)
+	return res
+}
+
+// TLSInfo reports the wrapped engine's handshake for output.
+func (o observedEngine) TLSInfo() *client.TLSInfo { return tlsInfo(o.Engine) }
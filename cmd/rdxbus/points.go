@@ -0,0 +1,210 @@
+// cmd/rdxbus/points.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/planner"
+	"github.com/tamzrod/rdxbus/internal/regmap"
+	"github.com/tamzrod/rdxbus/internal/stats"
+)
+
+// runMap reads the selected register map points with as few requests
+// as the planner allows and shows one row per point, once or every
+// -poll interval. A failed request marks its points and, for a single
+// read, makes the exit status non-zero.
+func runMap(cfg *config.Config) {
+	points := loadPoints(cfg)
+
+	eng := newEngine(cfg)
+	defer closeEngine(eng)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// One planner for the session, so splits learned in one poll
+	// sample shape the next.
+	pl := plannerFromConfig(cfg)
+
+	if cfg.Poll > 0 {
+		reg, stopMetrics := startMetrics(cfg, &stats.Counters{}, stats.NewHistogram())
+		defer stopMetrics()
+
+		exec := observed(eng, reg)
+		err := poll(ctx, cfg, reg, func(ctx context.Context) output.Output {
+			return mapSample(ctx, cfg, exec, pl, points)
+		})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "csv error:", err)
+			closeEngine(eng)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	out := mapSample(ctx, cfg, eng, pl, points)
+	emit(os.Stdout, cfg, out)
+
+	if out.Error != "" {
+		closeEngine(eng)
+		os.Exit(1)
+	}
+}
+
+// loadPoints loads the -map file and selects the -point names, or
+// every readable point. A bad map ends the run like any other
+// configuration error.
+func loadPoints(cfg *config.Config) []regmap.Point {
+	m, err := regmap.Load(cfg.MapFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	points, err := m.Select(cfg.Points...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	return points
+}
+
+// mapSample reads the map points once. When a request fails, its
+// points carry the error and Output.Error says how many failed.
+func mapSample(ctx context.Context, cfg *config.Config, eng engine.Engine, pl *planner.Planner, points []regmap.Point) output.Output {
+	start := time.Now()
+	batches := pl.Plan(regmap.Spans(points))
+	outcomes := pl.Execute(ctx, eng, batches, cfg.UnitID, cfg.Timeout)
+
+	var (
+		rows    []output.Row
+		latency time.Duration
+		failed  int
+		scaled  bool
+	)
+	for _, o := range outcomes {
+		latency += o.Result.Duration
+		b := regmap.Group(o.Batch, points)
+		readings, err := splitBatch(b, o.Result)
+		if err != nil {
+			failed += len(b.Points)
+			for _, p := range b.Points {
+				rows = append(rows, pointRow(regmap.Reading{Point: p}, err))
+			}
+			continue
+		}
+		for _, r := range readings {
+			if r.Err != nil {
+				failed++
+			}
+			scaled = scaled || r.Scaled != nil
+			rows = append(rows, pointRow(r, r.Err))
+		}
+	}
+
+	columns := []output.Column{
+		{Key: "point", Title: "Point"},
+		{Key: "fc", Title: "FC"},
+		{Key: "address", Title: "Address"},
+		{Key: "raw", Title: "Raw"},
+		{Key: "value", Title: "Value"},
+	}
+	if scaled {
+		columns = append(columns, output.Column{Key: "scaled", Title: "Scaled"})
+	}
+	if failed > 0 {
+		columns = append(columns,
+			output.Column{Key: "exception", Title: "Exception"},
+			output.Column{Key: "error", Title: "Error"},
+		)
+	}
+
+	msg := fmt.Sprintf("points: %d  requests: %d", len(points), len(batches))
+	if n := len(outcomes) - len(batches); n > 0 {
+		msg += fmt.Sprintf("  split after exceptions: %d", n)
+	}
+
+	out := output.Output{
+		Meta: withTLS(output.Meta{
+			Mode:      "read",
+			Target:    targetLabel(cfg),
+			UnitID:    cfg.UnitID,
+			Latency:   latency,
+			Timestamp: start,
+		}, eng),
+		Message: msg + "\n",
+		Table:   &output.Table{Columns: columns, Rows: rows},
+	}
+	if failed > 0 {
+		out.Error = fmt.Sprintf("%d of %d points failed", failed, len(points))
+	}
+	return out
+}
+
+// plannerFromConfig maps -max-gap, -max-quantity and -adaptive to a
+// read planner.
+func plannerFromConfig(cfg *config.Config) *planner.Planner {
+	pl := &planner.Planner{
+		Limits:   planner.Limits{MaxGap: uint16(cfg.MaxGap)},
+		Adaptive: cfg.Adaptive,
+	}
+	if cfg.MaxQuantity > 0 {
+		pl.Limits.MaxQuantity = map[uint8]uint16{}
+		for fc := uint8(1); fc <= 4; fc++ {
+			pl.Limits.MaxQuantity[fc] = uint16(cfg.MaxQuantity)
+		}
+	}
+	return pl
+}
+
+// splitBatch splits the result of one planned read into point readings.
+func splitBatch(b regmap.Batch, res engine.Result) ([]regmap.Reading, error) {
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	values, err := format.DecodeReadValues(res.Raw, b.Function, b.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return b.Split(values)
+}
+
+// pointRow shows one reading, or the error of the read that covered it.
+func pointRow(r regmap.Reading, err error) output.Row {
+	p := r.Point
+	cells := map[string]any{
+		"point":   p.Name,
+		"fc":      int(p.Function),
+		"address": int(p.Address),
+	}
+	if err != nil {
+		cells["error"] = err.Error()
+		if me, ok := client.IsModbusException(err); ok {
+			cells["exception"] = int(me.Code)
+		}
+		return output.Row{Cells: cells}
+	}
+
+	if p.Function == 1 || p.Function == 2 {
+		cells["raw"] = r.Raw[0]
+	} else {
+		cells["raw"] = rawWords(r.Raw)
+	}
+	cells["value"] = r.Value
+	if r.Scaled != nil {
+		cells["scaled"] = *r.Scaled
+	}
+	return output.Row{Cells: cells}
+}
@@ -0,0 +1,76 @@
+// cmd/rdxbus/records.go
+package main
+
+import (
+	"fmt"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+// recordTable decodes an FC 20 (file records) or FC 24 (FIFO queue)
+// response into a table.
+func recordTable(req engine.Request, pdu []byte) (*output.Table, error) {
+	if req.FunctionCode == 24 {
+		values, err := format.DecodeFIFO(pdu)
+		if err != nil {
+			return nil, err
+		}
+		return buildFIFOTable(values), nil
+	}
+
+	groups, err := format.DecodeFileRecords(pdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) != len(req.Records) {
+		return nil, fmt.Errorf("got %d file records, requested %d", len(groups), len(req.Records))
+	}
+	return buildFileRecordTable(req.Records, groups), nil
+}
+
+func buildFileRecordTable(records []client.FileRecord, groups [][]uint16) *output.Table {
+	var rows []output.Row
+	for i, rec := range records {
+		for j, v := range groups[i] {
+			rows = append(rows, output.Row{
+				Cells: map[string]any{
+					"file":   rec.File,
+					"record": int(rec.Record) + j,
+					"raw":    v,
+				},
+			})
+		}
+	}
+
+	return &output.Table{
+		Columns: []output.Column{
+			{Key: "file", Title: "File"},
+			{Key: "record", Title: "Record"},
+			{Key: "raw", Title: "Raw"},
+		},
+		Rows: rows,
+	}
+}
+
+func buildFIFOTable(values []uint16) *output.Table {
+	rows := make([]output.Row, 0, len(values))
+	for i, v := range values {
+		rows = append(rows, output.Row{
+			Cells: map[string]any{
+				"index": i,
+				"raw":   v,
+			},
+		})
+	}
+
+	return &output.Table{
+		Columns: []output.Column{
+			{Key: "index", Title: "Index"},
+			{Key: "raw", Title: "Raw"},
+		},
+		Rows: rows,
+	}
+}
@@ -0,0 +1,113 @@
+// cmd/rdxbus/stress.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/config"
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/scheduler"
+	"github.com/tamzrod/rdxbus/internal/stats"
+	"github.com/tamzrod/rdxbus/internal/worker"
+)
+
+func runStress(cfg *config.Config) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var policy scheduler.Policy
+	total := cfg.Duration
+
+	if len(cfg.RampRates) > 0 {
+		policy = &scheduler.Ramp{
+			Rates:        cfg.RampRates,
+			StepDuration: cfg.StepDuration,
+		}
+		total = time.Duration(len(cfg.RampRates)) * cfg.StepDuration
+	} else {
+		policy = &scheduler.Rate{PerSecond: cfg.Rate}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, total)
+	defer cancel()
+
+	eng := newEngine(cfg)
+	defer closeEngine(eng)
+
+	req := requestFromConfig(cfg)
+
+	if !cfg.Quiet {
+		fmt.Printf("stress: target=%s conn=%s workers=%d ", targetLabel(cfg), cfg.ConnMode, cfg.Workers)
+		if cfg.ConnMode == "pool" && cfg.Connections > 0 {
+			fmt.Printf("connections=%d ", cfg.Connections)
+		}
+		if cfg.ConnMode == "pipeline" {
+			fmt.Printf("depth=%d ", cfg.PipelineDepth)
+		}
+		if len(cfg.RampRates) > 0 {
+			fmt.Printf("ramp=%v step=%s\n", cfg.RampRates, cfg.StepDuration)
+		} else {
+			fmt.Printf("rate=%d duration=%s\n", cfg.Rate, cfg.Duration)
+		}
+	}
+
+	counters := &stats.Counters{}
+	hist := stats.NewHistogram()
+
+	_, stopMetrics := startMetrics(cfg, counters, hist)
+	defer stopMetrics()
+
+	start := time.Now()
+	results := worker.Spawn(ctx, cfg.Workers, eng, req, policy.Run(ctx))
+
+	// One collector goroutine classifies results; a metrics scrape
+	// may read the counters and histogram meanwhile.
+	for res := range results {
+		observe(counters, hist, res.EngineResult)
+	}
+
+	report := stats.BuildReport(time.Since(start), counters, hist.Snapshot())
+
+	if !cfg.Quiet {
+		fmt.Println()
+		fmt.Println("STRESS RESULT")
+		fmt.Println()
+	}
+	fmt.Print(report)
+	printConnStats(os.Stdout, eng)
+	if cfg.Transport == "udp" {
+		printDatagramStats(os.Stdout, eng)
+	}
+	printPipelineStats(os.Stdout, eng)
+}
+
+// observe classifies one result. Latency is recorded only when
+// the device answered (normal or exception response). Requests
+// cancelled by Ctrl+C or the end of the run are not device errors.
+func observe(c *stats.Counters, h *stats.Histogram, r engine.Result) {
+	if engine.IsCanceled(r.Err) {
+		c.IncCanceled()
+		return
+	}
+
+	c.IncRequests()
+
+	if _, ok := client.IsModbusException(r.Err); ok {
+		c.IncExceptions()
+		h.Record(r.Duration)
+		return
+	}
+	if r.Err != nil {
+		c.IncOtherErrs()
+		return
+	}
+
+	c.IncOK()
+	h.Record(r.Duration)
+}
@@ -0,0 +1,108 @@
+// internal/client/ascii.go
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// maxASCIIFrame is the longest ASCII ADU: ':' + hex(unit, 253-byte PDU,
+// LRC) + CRLF.
+const maxASCIIFrame = 1 + 2*(1+253+1) + 2
+
+// LRC computes the Modbus ASCII longitudinal redundancy check: the
+// two's complement of the 8-bit sum of b.
+func LRC(b []byte) byte {
+	var sum byte
+	for _, v := range b {
+		sum += v
+	}
+	return -sum
+}
+
+// EncodeASCII builds the ASCII ADU ":" + hex([unitID][pdu...][LRC]) + CRLF
+// with upper-case hex digits.
+func EncodeASCII(unitID uint8, pdu []byte) []byte {
+	raw := make([]byte, 0, len(pdu)+2)
+	raw = append(raw, unitID)
+	raw = append(raw, pdu...)
+	raw = append(raw, LRC(raw))
+
+	adu := make([]byte, 1+2*len(raw)+2)
+	adu[0] = ':'
+	for i, v := range raw {
+		const digits = "0123456789ABCDEF"
+		adu[1+2*i] = digits[v>>4]
+		adu[2+2*i] = digits[v&0x0F]
+	}
+	adu[len(adu)-2] = '\r'
+	adu[len(adu)-1] = '\n'
+	return adu
+}
+
+// ReadASCII reads one ASCII response to a request for unitID and fc and
+// returns its PDU [FC][Data...]. Characters before the ':' start mark
+// are skipped; the frame ends at CRLF. A bad LRC is a ChecksumError,
+// never a Modbus exception. r is read one character at a time, so it
+// should bound the whole frame, as Connection.Frame does.
+func ReadASCII(r FullReader, unitID, fc uint8) ([]byte, error) {
+	c := make([]byte, 1)
+
+	for skipped := 0; ; skipped++ {
+		if skipped > maxASCIIFrame {
+			return nil, fmt.Errorf("ascii start ':' not found")
+		}
+		if err := r.ReadFull(c); err != nil {
+			return nil, err
+		}
+		if c[0] == ':' {
+			break
+		}
+	}
+
+	line := make([]byte, 0, 64)
+	for {
+		if err := r.ReadFull(c); err != nil {
+			return nil, err
+		}
+		if c[0] == '\n' {
+			break
+		}
+		if len(line) >= maxASCIIFrame {
+			return nil, fmt.Errorf("ascii frame exceeds %d characters", maxASCIIFrame)
+		}
+		line = append(line, c[0])
+	}
+
+	if len(line) == 0 || line[len(line)-1] != '\r' {
+		return nil, fmt.Errorf("ascii frame not terminated by CRLF")
+	}
+	line = line[:len(line)-1]
+
+	raw := make([]byte, hex.DecodedLen(len(line)))
+	if _, err := hex.Decode(raw, line); err != nil {
+		return nil, fmt.Errorf("ascii frame: %w", err)
+	}
+	if len(raw) < 4 {
+		return nil, fmt.Errorf("ascii frame too short: %d bytes", len(raw))
+	}
+
+	body := raw[:len(raw)-1]
+	if got, want := raw[len(raw)-1], LRC(body); got != want {
+		return nil, &ChecksumError{Kind: "lrc", Got: uint16(got), Want: uint16(want)}
+	}
+
+	if body[0] != unitID {
+		return nil, fmt.Errorf("unit id mismatch: got %d expected %d", body[0], unitID)
+	}
+
+	// An exception echoes the request's function code with bit 7 set.
+	got := body[1]
+	if got&0x7F != fc {
+		return nil, fmt.Errorf("function code mismatch: got %d expected %d", got, fc)
+	}
+	if got&0x80 != 0 {
+		return nil, &ModbusExceptionError{Function: fc, Code: body[2]}
+	}
+	return body[1:], nil
+}
@@ -0,0 +1,93 @@
+// internal/client/ascii_test.go
+package client
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// bufReader serves ReadFull from a fixed byte slice.
+type bufReader struct{ b []byte }
+
+func (r *bufReader) ReadFull(p []byte) error {
+	if len(r.b) < len(p) {
+		return errors.New("short read")
+	}
+	copy(p, r.b)
+	r.b = r.b[len(p):]
+	return nil
+}
+
+func TestEncodeASCII(t *testing.T) {
+	got := EncodeASCII(1, []byte{3, 0x00, 0x00, 0x00, 0x01})
+	want := []byte(":010300000001FB\r\n")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got %q want %q", got, want)
+	}
+}
+
+func TestReadASCII(t *testing.T) {
+	// Noise before the start mark is skipped.
+	r := &bufReader{b: append([]byte("\x00\r\n"), EncodeASCII(1, []byte{3, 2, 0x12, 0x34})...)}
+	pdu, err := ReadASCII(r, 1, 3)
+	if err != nil {
+		t.Fatalf("ReadASCII error: %v", err)
+	}
+	if !bytes.Equal(pdu, []byte{3, 2, 0x12, 0x34}) {
+		t.Fatalf("unexpected pdu % x", pdu)
+	}
+
+	_, err = ReadASCII(&bufReader{b: EncodeASCII(1, []byte{0x83, 2})}, 1, 3)
+	if me, ok := IsModbusException(err); !ok || me.Code != 2 {
+		t.Fatalf("expected exception 2, got %v", err)
+	}
+
+	_, err = ReadASCII(&bufReader{b: EncodeASCII(1, []byte{0x84, 2})}, 1, 3)
+	if _, ok := IsModbusException(err); ok || err == nil {
+		t.Fatalf("expected function code mismatch, got %v", err)
+	}
+
+	_, err = ReadASCII(&bufReader{b: []byte(":0103021234B0\r\n")}, 1, 3)
+	var ce *ChecksumError
+	if !errors.As(err, &ce) || ce.Kind != "lrc" || ce.Want != 0xB4 {
+		t.Fatalf("expected lrc error, got %v", err)
+	}
+}
+
+func TestReadASCII_FrameDeadline(t *testing.T) {
+	for name, stream := range map[string][]byte{
+		"garbage": bytes.Repeat([]byte{0x55}, maxASCIIFrame),
+		"slow":    EncodeASCII(1, []byte{3, 2, 0x12, 0x34}),
+	} {
+		t.Run(name, func(t *testing.T) {
+			local, remote := net.Pipe()
+			defer local.Close()
+			defer remote.Close()
+
+			// One character every 10ms: each read is well inside the
+			// timeout, the whole frame is not.
+			go func() {
+				for _, b := range stream {
+					time.Sleep(10 * time.Millisecond)
+					if _, err := remote.Write([]byte{b}); err != nil {
+						return
+					}
+				}
+			}()
+
+			c := &Connection{conn: local, timeout: 50 * time.Millisecond}
+			start := time.Now()
+			_, err := ReadASCII(c.Frame(), 1, 3)
+			var ne net.Error
+			if !errors.As(err, &ne) || !ne.Timeout() {
+				t.Fatalf("expected a timeout, got %v", err)
+			}
+			if d := time.Since(start); d > 500*time.Millisecond {
+				t.Fatalf("frame read took %v with a 50ms timeout", d)
+			}
+		})
+	}
+}
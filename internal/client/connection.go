@@ -1,24 +1,58 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"os"
+	"syscall"
 	"time"
 )
 
+// link is the byte stream under a Connection: a TCP or TLS socket, a
+// serial port or a connected UDP socket. All support per-call deadlines.
+type link interface {
+	io.ReadWriteCloser
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
 type Connection struct {
-	conn    net.Conn
+	conn    link
 	timeout time.Duration
+
+	framing Framing
+
+	// datagram is set for UDP: each Write is one datagram and
+	// responses are read whole with ReadDatagram. retries is how
+	// often a request is resent after a timeout.
+	datagram bool
+	retries  int
+
+	// tls describes the handshake of a Modbus/TCP Security connection.
+	tls *TLSInfo
+
+	// silence is the idle time the line needs between frames
+	// (RTU t3.5); last is the end of the previous read or write.
+	silence time.Duration
+	last    time.Time
 }
 
 // Dial opens a TCP connection to the Modbus target.
 func Dial(address string, timeout time.Duration) (*Connection, error) {
+	return DialContext(context.Background(), address, timeout)
+}
+
+// DialContext is like Dial but gives up when ctx is done.
+func DialContext(ctx context.Context, address string, timeout time.Duration) (*Connection, error) {
 	dialer := net.Dialer{
 		Timeout:   timeout,
 		KeepAlive: 30 * time.Second,
 	}
 
-	c, err := dialer.Dial("tcp", address)
+	c, err := dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
 		return nil, fmt.Errorf("dial failed: %w", err)
 	}
@@ -36,7 +70,27 @@ func Dial(address string, timeout time.Duration) (*Connection, error) {
 	}, nil
 }
 
-// Close closes the underlying TCP connection.
+// Framing reports how PDUs are wrapped on this connection.
+func (c *Connection) Framing() Framing {
+	return c.framing
+}
+
+// TLSInfo returns the TLS handshake details, or nil without TLS.
+func (c *Connection) TLSInfo() *TLSInfo {
+	return c.tls
+}
+
+// Datagram reports whether the connection is a UDP socket.
+func (c *Connection) Datagram() bool {
+	return c.datagram
+}
+
+// Retries is how many times a UDP request is resent after a timeout.
+func (c *Connection) Retries() int {
+	return c.retries
+}
+
+// Close closes the underlying socket or serial port.
 func (c *Connection) Close() error {
 	if c.conn != nil {
 		return c.conn.Close()
@@ -44,21 +98,35 @@ func (c *Connection) Close() error {
 	return nil
 }
 
-// Write sends raw bytes to the socket.
+// Write sends raw bytes to the socket, first waiting out the silent
+// interval the line needs after the previous frame.
 func (c *Connection) Write(b []byte) error {
+	if wait := time.Until(c.last.Add(c.silence)); wait > 0 {
+		time.Sleep(wait)
+	}
+
 	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
 		return err
 	}
 	_, err := c.conn.Write(b)
+	c.touch()
 	return err
 }
 
 // ReadFull reads exactly len(b) bytes.
 func (c *Connection) ReadFull(b []byte) error {
-	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+	return c.ReadFullUntil(b, time.Now().Add(c.timeout))
+}
+
+// ReadFullUntil reads exactly len(b) bytes before deadline.
+// A zero deadline blocks until data arrives or the connection closes.
+func (c *Connection) ReadFullUntil(b []byte, deadline time.Time) error {
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
 		return err
 	}
 
+	defer c.touch()
+
 	n := 0
 	for n < len(b) {
 		r, err := c.conn.Read(b[n:])
@@ -69,3 +137,54 @@ func (c *Connection) ReadFull(b []byte) error {
 	}
 	return nil
 }
+
+// Frame returns a reader for one response frame whose reads all share
+// one deadline, the timeout from now, so a slow or noisy line cannot
+// stretch a frame read byte by byte past the timeout.
+func (c *Connection) Frame() FullReader {
+	return frameReader{c: c, deadline: time.Now().Add(c.timeout)}
+}
+
+type frameReader struct {
+	c        *Connection
+	deadline time.Time
+}
+
+func (r frameReader) ReadFull(b []byte) error {
+	return r.c.ReadFullUntil(b, r.deadline)
+}
+
+// touch records line activity for the silent interval.
+func (c *Connection) touch() {
+	if c.silence > 0 {
+		c.last = time.Now()
+	}
+}
+
+// SetTimeout changes the deadline applied to each Write and ReadFull.
+func (c *Connection) SetTimeout(timeout time.Duration) {
+	c.timeout = timeout
+}
+
+// IsBrokenConn reports whether err means the connection can no longer
+// be trusted (peer closed, reset, or timed out mid-transaction).
+// Modbus exceptions never break a connection.
+func IsBrokenConn(err error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := IsModbusException(err); ok {
+		return false
+	}
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, os.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
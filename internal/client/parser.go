@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 )
@@ -22,6 +23,10 @@ func IsModbusException(err error) (*ModbusExceptionError, bool) {
 	return me, ok
 }
 
+// maxMBAPLength is the largest valid MBAP length: the unit id and a
+// 253-byte PDU.
+const maxMBAPLength = 1 + 253
+
 type ResponseParser struct {
 	strict bool
 }
@@ -30,98 +35,251 @@ func NewResponseParser(strict bool) *ResponseParser {
 	return &ResponseParser{strict: strict}
 }
 
+// Parse reads the response body that follows the MBAP header hdr
+// and returns the PDU [FC][Data...] as a slice of pduBuf.
 func (p *ResponseParser) Parse(
-	conn *Connection,
+	conn FullReader,
 	expectedTxID uint16,
 	expectedFC uint8,
 	hdr []byte,
 	pduBuf []byte,
-) error {
+) ([]byte, error) {
 
 	// MBAP
 	txID := binary.BigEndian.Uint16(hdr[0:2])
 	if txID != expectedTxID {
-		return fmt.Errorf("txid mismatch: got %d expected %d", txID, expectedTxID)
+		return nil, fmt.Errorf("txid mismatch: got %d expected %d", txID, expectedTxID)
 	}
 	if binary.BigEndian.Uint16(hdr[2:4]) != 0 {
-		return fmt.Errorf("invalid protocol id")
+		return nil, fmt.Errorf("invalid protocol id")
 	}
 
 	length := binary.BigEndian.Uint16(hdr[4:6])
 	if length < 1 {
-		return fmt.Errorf("invalid mbap length")
+		return nil, fmt.Errorf("invalid mbap length")
 	}
 
-	// STRICT: read exactly length bytes and validate unitID+FC framing
+	// STRICT: read exactly length bytes and validate unitID+FC framing.
+	// The MBAP length counts the unit id, which arrived with hdr.
 	if p.strict {
 		if int(length) > len(pduBuf) {
-			return fmt.Errorf("pdu buffer too small")
+			return nil, fmt.Errorf("pdu buffer too small")
+		}
+		pduBuf[0] = hdr[6]
+		if err := conn.ReadFull(pduBuf[1:length]); err != nil {
+			return nil, err
 		}
-		if err := conn.ReadFull(pduBuf[:length]); err != nil {
-			return err
+		if err := ValidateFrame(pduBuf[:length], expectedFC); err != nil {
+			return nil, err
 		}
-		return validateStrictPDU(pduBuf[:length], expectedFC)
+		return pduBuf[1:length], nil
 	}
 
-	// LENIENT: auto-detect whether payload begins with FC or UnitID.
-	// We will read enough bytes to extract: FC (+ optional bytecount) or exception code.
+	// LENIENT: auto-detect whether payload begins with FC or UnitID,
+	// then read the body by the function code's response layout.
 
 	// Read first 2 bytes of "payload"
 	if err := conn.ReadFull(pduBuf[:2]); err != nil {
-		return err
+		return nil, err
 	}
 
 	b0 := pduBuf[0]
-	b1 := pduBuf[1]
 
 	// Case A: FC-first (b0 is fc or exception fc)
+	// Case B: UnitID + FC (b1 is fc or exception fc)
+	fcIdx := 1
 	if b0 == expectedFC || b0 == (expectedFC|0x80) {
-		fc := b0
-		if fc&0x80 != 0 {
-			// b1 is exception code
-			return &ModbusExceptionError{Function: fc & 0x7F, Code: b1}
+		fcIdx = 0
+	}
+
+	fc := pduBuf[fcIdx]
+	if fc&0x80 == 0 && fc != expectedFC {
+		return nil, fmt.Errorf("function code mismatch: got %d expected %d", fc, expectedFC)
+	}
+
+	end, err := readBody(conn, pduBuf, fcIdx, 1-fcIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bytes the MBAP length counts beyond the layout (padding) would
+	// otherwise be taken for the next response's header.
+	if pad := int(length) - 1 - end; pad > 0 && length <= maxMBAPLength {
+		if err := conn.ReadFull(make([]byte, pad)); err != nil {
+			return nil, err
 		}
+	}
+
+	if fc&0x80 != 0 {
+		return nil, &ModbusExceptionError{Function: fc & 0x7F, Code: pduBuf[fcIdx+1]}
+	}
+	return pduBuf[fcIdx:end], nil
+}
 
-		// normal: b1 is bytecount
-		byteCount := int(b1)
-		if byteCount > 0 {
-			if err := conn.ReadFull(pduBuf[2 : 2+byteCount]); err != nil {
-				return err
+// readBody reads the rest of a response whose function code is at
+// buf[fcIdx] and whose first have body bytes are already in buf.
+// It returns the end offset of the PDU within buf.
+func readBody(r FullReader, buf []byte, fcIdx, have int) (int, error) {
+	fc := buf[fcIdx]
+	start := fcIdx + 1
+
+	for {
+		n, known, err := bodyLen(fc, buf[start:start+have])
+		if err != nil {
+			return 0, err
+		}
+		if n > have {
+			if start+n > len(buf) {
+				return 0, fmt.Errorf("pdu buffer too small")
 			}
+			if err := r.ReadFull(buf[start+have : start+n]); err != nil {
+				return 0, err
+			}
+			have = n
+		}
+		if known {
+			return start + n, nil
 		}
-		return nil
 	}
+}
 
-	// Case B: UnitID + FC (b1 is fc or exception fc)
-	fc := b1
+// bodyLen reports the length of a response body (the bytes after the
+// function code) from the bytes seen so far. It returns (n, true) once
+// the length is known, or (n, false) meaning n bytes must be available
+// before asking again.
+func bodyLen(fc uint8, body []byte) (int, bool, error) {
 	if fc&0x80 != 0 {
-		// need 1 more byte for exception code
-		if err := conn.ReadFull(pduBuf[2:3]); err != nil {
-			return err
+		return 1, true, nil // exception code
+	}
+
+	switch fc {
+	case 1, 2, 3, 4, 12, 17, 20, 21, 23:
+		// [ByteCount][Data...]
+		if len(body) < 1 {
+			return 1, false, nil
+		}
+		return 1 + int(body[0]), true, nil
+
+	case 5, 6:
+		// [Address(2)][Value(2)] echo
+		return 4, true, nil
+
+	case 7:
+		// [ExceptionStatus]
+		return 1, true, nil
+
+	case 8:
+		// [SubFunction(2)][Data(2)]
+		return 4, true, nil
+
+	case 11:
+		// [Status(2)][EventCount(2)]
+		return 4, true, nil
+
+	case 15, 16:
+		// [Address(2)][Quantity(2)] echo
+		return 4, true, nil
+
+	case 22:
+		// [Address(2)][AndMask(2)][OrMask(2)] echo
+		return 6, true, nil
+
+	case 24:
+		// [ByteCount(2)][FIFOCount(2)][Values...]
+		if len(body) < 2 {
+			return 2, false, nil
+		}
+		return 2 + int(binary.BigEndian.Uint16(body[0:2])), true, nil
+
+	case 43:
+		// [MEI][Code][Conformity][MoreFollows][NextObjectID][NumObjects]
+		// followed by NumObjects x [ObjectID][Length][Value...]
+		const head = 6
+		if len(body) < head {
+			return head, false, nil
+		}
+		n := head
+		for i := 0; i < int(body[5]); i++ {
+			if len(body) < n+2 {
+				return n + 2, false, nil
+			}
+			n += 2 + int(body[n+1])
 		}
-		return &ModbusExceptionError{Function: fc & 0x7F, Code: pduBuf[2]}
+		return n, true, nil
+
+	default:
+		return 0, false, fmt.Errorf("unsupported function code: %d", fc)
 	}
+}
 
-	if fc != expectedFC {
-		return fmt.Errorf("function code mismatch: got %d expected %d", fc, expectedFC)
+// ValidateEcho checks a write or diagnostics response that must repeat
+// the start of the request PDU. Read responses are not echoes and
+// always pass.
+func ValidateEcho(pdu, reqPDU []byte) error {
+	n := echoLen(reqPDU)
+	if n == 0 {
+		return nil
+	}
+	if len(pdu) < n || len(reqPDU) < n || !bytes.Equal(pdu[:n], reqPDU[:n]) {
+		return fmt.Errorf("write echo mismatch: got % x expected % x", pdu, reqPDU[:n])
+	}
+	return nil
+}
+
+// echoLen is the number of request PDU bytes a response repeats.
+func echoLen(reqPDU []byte) int {
+	switch reqPDU[0] {
+	case 5, 6:
+		return 5 // FC + Address + Value
+	case 15, 16:
+		return 5 // FC + Address + Quantity
+	case 8:
+		return 3 // FC + SubFunction
+	case 21:
+		return 2 + int(reqPDU[1]) // the whole request
+	case 22:
+		return 7 // FC + Address + AndMask + OrMask
+	default:
+		return 0
 	}
+}
 
-	// need 1 more byte for bytecount
-	if err := conn.ReadFull(pduBuf[2:3]); err != nil {
-		return err
+// FullReader reads exactly len(b) bytes.
+type FullReader interface {
+	ReadFull(b []byte) error
+}
+
+// ReadFrame reads one MBAP-framed response using the MBAP length field.
+// It returns the transaction id and the frame body [UnitID][FC][Data...]
+// stored in buf. No function code validation happens here.
+func ReadFrame(r FullReader, buf []byte) (uint16, []byte, error) {
+	var hdr [6]byte
+	if err := r.ReadFull(hdr[:]); err != nil {
+		return 0, nil, err
 	}
-	byteCount := int(pduBuf[2])
 
-	if byteCount > 0 {
-		if err := conn.ReadFull(pduBuf[3 : 3+byteCount]); err != nil {
-			return err
-		}
+	txID := binary.BigEndian.Uint16(hdr[0:2])
+	if binary.BigEndian.Uint16(hdr[2:4]) != 0 {
+		return txID, nil, fmt.Errorf("invalid protocol id")
 	}
 
-	return nil
+	length := int(binary.BigEndian.Uint16(hdr[4:6]))
+	if length < 2 {
+		return txID, nil, fmt.Errorf("invalid mbap length")
+	}
+	if length > len(buf) {
+		return txID, nil, fmt.Errorf("pdu buffer too small")
+	}
+
+	if err := r.ReadFull(buf[:length]); err != nil {
+		return txID, nil, err
+	}
+	return txID, buf[:length], nil
 }
 
-func validateStrictPDU(pdu []byte, expectedFC uint8) error {
+// ValidateFrame checks a strict frame body [UnitID][FC][Data...]
+// against the expected function code and decodes exceptions.
+func ValidateFrame(pdu []byte, expectedFC uint8) error {
 	if len(pdu) < 2 {
 		return fmt.Errorf("pdu too short")
 	}
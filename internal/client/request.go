@@ -4,8 +4,36 @@ import "encoding/binary"
 
 const (
 	mbapHeaderSize = 7
+
+	// Protocol limits for multiple writes (Modbus Application Protocol v1.1b3).
+	MaxWriteCoils     = 1968 // FC 15
+	MaxWriteRegisters = 123  // FC 16
+
+	MaxReadWriteRead  = 125 // FC 23 read part
+	MaxReadWriteWrite = 121 // FC 23 write part
+
+	// MEIReadDeviceID is the MEI type of Read Device Identification (FC 43).
+	MEIReadDeviceID = 14
+
+	// File record access (FC 20/21). Every sub-request uses reference
+	// type 6; record numbers are 0..9999 and the data of all
+	// sub-requests must fit the 251 bytes left after [FC][ByteCount].
+	FileRefType      = 6
+	MaxFileRecordNum = 9999
+	MaxFileData      = 251
+
+	MaxFIFOCount = 31 // FC 24
 )
 
+// FileRecord is one FC 20/21 sub-request. Length is the number of
+// registers to read (FC 20); FC 21 writes Data and ignores Length.
+type FileRecord struct {
+	File   uint16
+	Record uint16
+	Length uint16
+	Data   []uint16
+}
+
 type Request struct {
 	txID uint16
 }
@@ -28,19 +56,298 @@ func (r *Request) BuildReadRequest(
 	address uint16,
 	quantity uint16,
 ) []byte {
+	return r.buildFixed(buf, unitID, functionCode, address, quantity)
+}
 
-	r.txID++
+// BuildWriteSingleRequest builds Write Single Coil (FC 5) or
+// Write Single Register (FC 6). For FC 5 any non-zero value is
+// sent as ON (0xFF00). buf must be at least 12 bytes.
+func (r *Request) BuildWriteSingleRequest(
+	buf []byte,
+	unitID uint8,
+	functionCode uint8,
+	address uint16,
+	value uint16,
+) []byte {
+	if functionCode == 5 && value != 0 {
+		value = 0xFF00
+	}
+	return r.buildFixed(buf, unitID, functionCode, address, value)
+}
 
-	// MBAP Header
-	binary.BigEndian.PutUint16(buf[0:2], r.txID)
-	binary.BigEndian.PutUint16(buf[2:4], 0) // Protocol ID
-	binary.BigEndian.PutUint16(buf[4:6], 6) // Length = UnitID + PDU(5)
-	buf[6] = unitID
+// BuildWriteMultipleCoilsRequest builds Write Multiple Coils (FC 15).
+// Each value is one coil (non-zero = ON); coils are packed LSB-first,
+// the inverse of the FC 1/2 response layout.
+// buf must be at least 13 + (len(values)+7)/8 bytes.
+func (r *Request) BuildWriteMultipleCoilsRequest(
+	buf []byte,
+	unitID uint8,
+	address uint16,
+	values []uint16,
+) []byte {
+
+	byteCount := (len(values) + 7) / 8
+	pduLen := 6 + byteCount
+
+	r.putMBAP(buf, unitID, pduLen)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+pduLen]
+	pdu[0] = 15
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], uint16(len(values)))
+	pdu[5] = byte(byteCount)
+
+	data := pdu[6:]
+	for i := range data {
+		data[i] = 0
+	}
+	for i, v := range values {
+		if v != 0 {
+			data[i/8] |= 1 << uint(i%8)
+		}
+	}
+
+	return buf[:mbapHeaderSize+pduLen]
+}
+
+// BuildWriteMultipleRegistersRequest builds Write Multiple Registers (FC 16).
+// buf must be at least 13 + 2*len(values) bytes.
+func (r *Request) BuildWriteMultipleRegistersRequest(
+	buf []byte,
+	unitID uint8,
+	address uint16,
+	values []uint16,
+) []byte {
+
+	byteCount := 2 * len(values)
+	pduLen := 6 + byteCount
+
+	r.putMBAP(buf, unitID, pduLen)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+pduLen]
+	pdu[0] = 16
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], uint16(len(values)))
+	pdu[5] = byte(byteCount)
+
+	for i, v := range values {
+		binary.BigEndian.PutUint16(pdu[6+2*i:], v)
+	}
+
+	return buf[:mbapHeaderSize+pduLen]
+}
+
+// BuildMaskWriteRequest builds Mask Write Register (FC 22).
+// The device computes (current AND andMask) OR (orMask AND NOT andMask).
+// buf must be at least 14 bytes.
+func (r *Request) BuildMaskWriteRequest(
+	buf []byte,
+	unitID uint8,
+	address uint16,
+	andMask uint16,
+	orMask uint16,
+) []byte {
+
+	r.putMBAP(buf, unitID, 7)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+7]
+	pdu[0] = 22
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+	binary.BigEndian.PutUint16(pdu[3:5], andMask)
+	binary.BigEndian.PutUint16(pdu[5:7], orMask)
+
+	return buf[:mbapHeaderSize+7]
+}
+
+// BuildReadWriteMultipleRequest builds Read/Write Multiple Registers (FC 23).
+// The device performs the write before the read.
+// buf must be at least 17 + 2*len(values) bytes.
+func (r *Request) BuildReadWriteMultipleRequest(
+	buf []byte,
+	unitID uint8,
+	readAddress uint16,
+	readQuantity uint16,
+	writeAddress uint16,
+	values []uint16,
+) []byte {
+
+	byteCount := 2 * len(values)
+	pduLen := 10 + byteCount
+
+	r.putMBAP(buf, unitID, pduLen)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+pduLen]
+	pdu[0] = 23
+	binary.BigEndian.PutUint16(pdu[1:3], readAddress)
+	binary.BigEndian.PutUint16(pdu[3:5], readQuantity)
+	binary.BigEndian.PutUint16(pdu[5:7], writeAddress)
+	binary.BigEndian.PutUint16(pdu[7:9], uint16(len(values)))
+	pdu[9] = byte(byteCount)
+
+	for i, v := range values {
+		binary.BigEndian.PutUint16(pdu[10+2*i:], v)
+	}
+
+	return buf[:mbapHeaderSize+pduLen]
+}
+
+// BuildReadFileRecordRequest builds Read File Record (FC 20) with one
+// 7-byte sub-request per record. buf must be at least 9 + 7*len(records) bytes.
+func (r *Request) BuildReadFileRecordRequest(
+	buf []byte,
+	unitID uint8,
+	records []FileRecord,
+) []byte {
+
+	byteCount := 7 * len(records)
+	pduLen := 2 + byteCount
+
+	r.putMBAP(buf, unitID, pduLen)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+pduLen]
+	pdu[0] = 20
+	pdu[1] = byte(byteCount)
+
+	for i, rec := range records {
+		sub := pdu[2+7*i:]
+		sub[0] = FileRefType
+		binary.BigEndian.PutUint16(sub[1:3], rec.File)
+		binary.BigEndian.PutUint16(sub[3:5], rec.Record)
+		binary.BigEndian.PutUint16(sub[5:7], rec.Length)
+	}
+
+	return buf[:mbapHeaderSize+pduLen]
+}
+
+// BuildWriteFileRecordRequest builds Write File Record (FC 21). Each
+// sub-request writes len(rec.Data) registers.
+// buf must be at least 9 + sum(7 + 2*len(Data)) bytes.
+func (r *Request) BuildWriteFileRecordRequest(
+	buf []byte,
+	unitID uint8,
+	records []FileRecord,
+) []byte {
+
+	byteCount := 0
+	for _, rec := range records {
+		byteCount += 7 + 2*len(rec.Data)
+	}
+	pduLen := 2 + byteCount
+
+	r.putMBAP(buf, unitID, pduLen)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+pduLen]
+	pdu[0] = 21
+	pdu[1] = byte(byteCount)
+
+	sub := pdu[2:]
+	for _, rec := range records {
+		sub[0] = FileRefType
+		binary.BigEndian.PutUint16(sub[1:3], rec.File)
+		binary.BigEndian.PutUint16(sub[3:5], rec.Record)
+		binary.BigEndian.PutUint16(sub[5:7], uint16(len(rec.Data)))
+		for i, v := range rec.Data {
+			binary.BigEndian.PutUint16(sub[7+2*i:], v)
+		}
+		sub = sub[7+2*len(rec.Data):]
+	}
+
+	return buf[:mbapHeaderSize+pduLen]
+}
+
+// BuildReadFIFORequest builds Read FIFO Queue (FC 24) for the FIFO
+// pointer register at address. buf must be at least 10 bytes.
+func (r *Request) BuildReadFIFORequest(
+	buf []byte,
+	unitID uint8,
+	address uint16,
+) []byte {
+
+	r.putMBAP(buf, unitID, 3)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+3]
+	pdu[0] = 24
+	binary.BigEndian.PutUint16(pdu[1:3], address)
+
+	return buf[:mbapHeaderSize+3]
+}
+
+// BuildNoDataRequest builds a request whose PDU is only the function
+// code: Read Exception Status (FC 7), Get Comm Event Counter (FC 11),
+// Get Comm Event Log (FC 12) and Report Server ID (FC 17).
+// buf must be at least 8 bytes.
+func (r *Request) BuildNoDataRequest(
+	buf []byte,
+	unitID uint8,
+	functionCode uint8,
+) []byte {
+
+	r.putMBAP(buf, unitID, 1)
+	buf[mbapHeaderSize] = functionCode
+
+	return buf[:mbapHeaderSize+1]
+}
+
+// BuildDiagnosticsRequest builds Diagnostics (FC 8) with one data word.
+// buf must be at least 12 bytes.
+func (r *Request) BuildDiagnosticsRequest(
+	buf []byte,
+	unitID uint8,
+	subFunction uint16,
+	data uint16,
+) []byte {
+	return r.buildFixed(buf, unitID, 8, subFunction, data)
+}
+
+// BuildReadDeviceIDRequest builds Read Device Identification
+// (FC 43 / MEI 14). code selects basic (1), regular (2), extended (3)
+// or individual (4) access; objectID is the first object to return.
+// buf must be at least 11 bytes.
+func (r *Request) BuildReadDeviceIDRequest(
+	buf []byte,
+	unitID uint8,
+	code uint8,
+	objectID uint8,
+) []byte {
+
+	r.putMBAP(buf, unitID, 4)
+
+	pdu := buf[mbapHeaderSize : mbapHeaderSize+4]
+	pdu[0] = 43
+	pdu[1] = MEIReadDeviceID
+	pdu[2] = code
+	pdu[3] = objectID
+
+	return buf[:mbapHeaderSize+4]
+}
+
+// buildFixed builds the 12-byte frame shared by FC 1–6 and 8:
+// MBAP + [FC][Address or SubFunction(2)][Quantity or Value(2)].
+func (r *Request) buildFixed(
+	buf []byte,
+	unitID uint8,
+	functionCode uint8,
+	address uint16,
+	word uint16,
+) []byte {
+
+	r.putMBAP(buf, unitID, 5)
 
 	// PDU
 	buf[7] = functionCode
 	binary.BigEndian.PutUint16(buf[8:10], address)
-	binary.BigEndian.PutUint16(buf[10:12], quantity)
+	binary.BigEndian.PutUint16(buf[10:12], word)
 
 	return buf[:12]
 }
+
+// putMBAP writes the MBAP header for a PDU of pduLen bytes
+// using the next transaction id.
+func (r *Request) putMBAP(buf []byte, unitID uint8, pduLen int) {
+	r.txID++
+
+	binary.BigEndian.PutUint16(buf[0:2], r.txID)
+	binary.BigEndian.PutUint16(buf[2:4], 0)                // Protocol ID
+	binary.BigEndian.PutUint16(buf[4:6], uint16(1+pduLen)) // Length = UnitID + PDU
+	buf[6] = unitID
+}
@@ -0,0 +1,96 @@
+// internal/client/rtu.go
+package client
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// ChecksumError reports a frame whose CRC (RTU) or LRC (ASCII) does
+// not match its contents. The frame is corrupt; it is not a Modbus
+// exception.
+type ChecksumError struct {
+	Kind string // "crc" or "lrc"
+	Got  uint16
+	Want uint16
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("%s mismatch: got 0x%04X expected 0x%04X", e.Kind, e.Got, e.Want)
+}
+
+// CRC16 computes the Modbus RTU CRC (polynomial 0xA001, initial 0xFFFF).
+// On the wire it is sent low byte first.
+func CRC16(b []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, v := range b {
+		crc ^= uint16(v)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// RTUSilence is the t3.5 inter-frame silence for baud. Above 19200 baud
+// the spec fixes it at 1.75 ms.
+func RTUSilence(baud int) time.Duration {
+	if baud <= 0 || baud > 19200 {
+		return 1750 * time.Microsecond
+	}
+	// 11 bits per character (start, 8 data, parity or 2nd stop, stop).
+	return time.Duration(3.5 * 11 * float64(time.Second) / float64(baud))
+}
+
+// EncodeRTU builds the RTU ADU [unitID][pdu...][CRC lo][CRC hi].
+func EncodeRTU(unitID uint8, pdu []byte) []byte {
+	adu := make([]byte, 0, len(pdu)+3)
+	adu = append(adu, unitID)
+	adu = append(adu, pdu...)
+	crc := CRC16(adu)
+	return append(adu, byte(crc), byte(crc>>8))
+}
+
+// ReadRTU reads one RTU response to a request for unitID and fc into
+// buf and returns its PDU [FC][Data...]. RTU has no length field, so
+// the frame is sized from the function code's response layout.
+func ReadRTU(r FullReader, buf []byte, unitID, fc uint8) ([]byte, error) {
+	if err := r.ReadFull(buf[:2]); err != nil {
+		return nil, err
+	}
+	if buf[0] != unitID {
+		return nil, fmt.Errorf("unit id mismatch: got %d expected %d", buf[0], unitID)
+	}
+
+	// An exception echoes the request's function code with bit 7 set.
+	got := buf[1]
+	if got&0x7F != fc {
+		return nil, fmt.Errorf("function code mismatch: got %d expected %d", got, fc)
+	}
+
+	end, err := readBody(r, buf, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if end+2 > len(buf) {
+		return nil, fmt.Errorf("pdu buffer too small")
+	}
+	if err := r.ReadFull(buf[end : end+2]); err != nil {
+		return nil, err
+	}
+
+	want := CRC16(buf[:end])
+	if crc := binary.LittleEndian.Uint16(buf[end : end+2]); crc != want {
+		return nil, &ChecksumError{Kind: "crc", Got: crc, Want: want}
+	}
+
+	if got&0x80 != 0 {
+		return nil, &ModbusExceptionError{Function: got & 0x7F, Code: buf[2]}
+	}
+	return buf[1:end], nil
+}
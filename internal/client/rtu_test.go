@@ -0,0 +1,41 @@
+// internal/client/rtu_test.go
+package client
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestEncodeRTU(t *testing.T) {
+	// Read 10 holding registers from unit 1: the CRC is sent low byte first.
+	got := EncodeRTU(1, []byte{3, 0x00, 0x00, 0x00, 0x0A})
+	want := []byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x0A, 0xC5, 0xCD}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got % x want % x", got, want)
+	}
+}
+
+func TestRTUSilence(t *testing.T) {
+	if got := RTUSilence(9600); got < 4*time.Millisecond || got > 4100*time.Microsecond {
+		t.Fatalf("9600 baud t3.5 = %v, want about 4ms", got)
+	}
+	if got := RTUSilence(115200); got != 1750*time.Microsecond {
+		t.Fatalf("115200 baud t3.5 = %v, want 1.75ms", got)
+	}
+}
+
+func TestReadRTU_Exception(t *testing.T) {
+	buf := make([]byte, 260)
+
+	_, err := ReadRTU(&bufReader{b: EncodeRTU(1, []byte{0x83, 2})}, buf, 1, 3)
+	if me, ok := IsModbusException(err); !ok || me.Function != 3 || me.Code != 2 {
+		t.Fatalf("expected fc 3 exception 2, got %v", err)
+	}
+
+	// An exception for another function code answers some other request.
+	_, err = ReadRTU(&bufReader{b: EncodeRTU(1, []byte{0x84, 2})}, buf, 1, 3)
+	if _, ok := IsModbusException(err); ok || err == nil {
+		t.Fatalf("expected function code mismatch, got %v", err)
+	}
+}
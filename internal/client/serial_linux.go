@@ -0,0 +1,110 @@
+// internal/client/serial_linux.go
+
+//go:build linux
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var baudRates = map[int]uint32{
+	1200:   syscall.B1200,
+	2400:   syscall.B2400,
+	4800:   syscall.B4800,
+	9600:   syscall.B9600,
+	19200:  syscall.B19200,
+	38400:  syscall.B38400,
+	57600:  syscall.B57600,
+	115200: syscall.B115200,
+	230400: syscall.B230400,
+	460800: syscall.B460800,
+	921600: syscall.B921600,
+}
+
+// OpenSerial opens and configures a serial port in raw mode.
+func OpenSerial(cfg SerialConfig, timeout time.Duration) (*Connection, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	speed, ok := baudRates[cfg.Baud]
+	if !ok {
+		return nil, fmt.Errorf("unsupported baud rate: %d", cfg.Baud)
+	}
+
+	f, err := os.OpenFile(cfg.Device, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open serial failed: %w", err)
+	}
+
+	if err := configureSerial(f, cfg, speed); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("configure %s: %w", cfg.Device, err)
+	}
+
+	return &Connection{
+		conn:    f,
+		timeout: timeout,
+	}, nil
+}
+
+func configureSerial(f *os.File, cfg SerialConfig, speed uint32) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var ioErr error
+	err = rc.Control(func(fd uintptr) {
+		var t syscall.Termios
+		if ioErr = ioctl(fd, syscall.TCGETS, uintptr(unsafe.Pointer(&t))); ioErr != nil {
+			return
+		}
+
+		// Raw mode: no echo, no line editing, no CR/LF translation.
+		t.Iflag = 0
+		t.Oflag = 0
+		t.Lflag = 0
+		// The speed lives in the CBAUD bits of Cflag.
+		t.Cflag = syscall.CREAD | syscall.CLOCAL | speed
+
+		if cfg.DataBits == 7 {
+			t.Cflag |= syscall.CS7
+		} else {
+			t.Cflag |= syscall.CS8
+		}
+		switch cfg.Parity {
+		case 'E':
+			t.Cflag |= syscall.PARENB
+			t.Iflag |= syscall.INPCK
+		case 'O':
+			t.Cflag |= syscall.PARENB | syscall.PARODD
+			t.Iflag |= syscall.INPCK
+		}
+		if cfg.StopBits == 2 {
+			t.Cflag |= syscall.CSTOPB
+		}
+
+		// Reads return as soon as one byte is available; deadlines
+		// are enforced by the runtime poller.
+		t.Cc[syscall.VMIN] = 1
+		t.Cc[syscall.VTIME] = 0
+
+		ioErr = ioctl(fd, syscall.TCSETS, uintptr(unsafe.Pointer(&t)))
+	})
+	if err != nil {
+		return err
+	}
+	return ioErr
+}
+
+func ioctl(fd, req, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, req, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// internal/client/serial_other.go
+
+//go:build !linux
+
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// OpenSerial is only implemented on Linux.
+func OpenSerial(cfg SerialConfig, timeout time.Duration) (*Connection, error) {
+	return nil, fmt.Errorf("serial ports are not supported on this platform")
+}
@@ -0,0 +1,177 @@
+// internal/client/tls.go
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"net"
+	"os"
+	"time"
+)
+
+// SecurePort is the registered port for Modbus/TCP Security.
+const SecurePort = 802
+
+// RoleOID identifies the Modbus/TCP Security role extension: an
+// ASN.1 UTF8String in the certificate naming the holder's role.
+var RoleOID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 50316, 802, 1}
+
+// TLSOptions describes a Modbus/TCP Security client: the CA bundle
+// that verifies the server, the client certificate for mutual
+// authentication, and protocol limits.
+type TLSOptions struct {
+	CAFile     string // PEM bundle; the system roots when empty
+	CertFile   string // client certificate (PEM); optional
+	KeyFile    string // client private key (PEM); required with CertFile
+	ServerName string // name checked in the server certificate; the target host when empty
+	MinVersion string // "1.2" (the Modbus/TCP Security minimum) or "1.3"
+}
+
+// Validate checks the option combination without touching files.
+func (o TLSOptions) Validate() error {
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return fmt.Errorf("tls client certificate and key must be given together")
+	}
+	if _, err := tlsVersion(o.MinVersion); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Config loads the certificates and builds the TLS client configuration.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	min, _ := tlsVersion(o.MinVersion)
+
+	cfg := &tls.Config{
+		MinVersion: min,
+		ServerName: o.ServerName,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls ca: no certificates in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+func tlsVersion(s string) (uint16, error) {
+	switch s {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("tls minimum version must be 1.2 or 1.3")
+	}
+}
+
+// TLSInfo describes a completed Modbus/TCP Security handshake.
+type TLSInfo struct {
+	Version     string // e.g. "TLS 1.3"
+	CipherSuite string
+	Subject     string // peer certificate subject
+	Role        string // peer role extension; empty when absent
+}
+
+// DialTLSContext opens a TCP connection to address and completes a
+// TLS handshake with cfg. The server name defaults to the host part
+// of address.
+func DialTLSContext(ctx context.Context, address string, cfg *tls.Config, timeout time.Duration) (*Connection, error) {
+	c, err := DialContext(ctx, address, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ServerName == "" {
+		cfg = cfg.Clone()
+		if cfg.ServerName, _, err = net.SplitHostPort(address); err != nil {
+			cfg.ServerName = address
+		}
+	}
+
+	tc := tls.Client(c.conn.(net.Conn), cfg)
+	hctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := tc.HandshakeContext(hctx); err != nil {
+		_ = tc.Close()
+		return nil, fmt.Errorf("tls handshake failed: %w", err)
+	}
+
+	info, err := handshakeInfo(tc.ConnectionState())
+	if err != nil {
+		_ = tc.Close()
+		return nil, err
+	}
+
+	c.conn = tc
+	c.tls = info
+	return c, nil
+}
+
+func handshakeInfo(st tls.ConnectionState) (*TLSInfo, error) {
+	info := &TLSInfo{
+		Version:     tlsVersionName(st.Version),
+		CipherSuite: tls.CipherSuiteName(st.CipherSuite),
+	}
+	if len(st.PeerCertificates) == 0 {
+		return info, nil
+	}
+
+	peer := st.PeerCertificates[0]
+	info.Subject = peer.Subject.String()
+
+	role, err := CertRole(peer)
+	if err != nil {
+		return nil, err
+	}
+	info.Role = role
+	return info, nil
+}
+
+// CertRole returns the role extension of cert, or "" when it has none.
+func CertRole(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(RoleOID) {
+			continue
+		}
+		var role string
+		if _, err := asn1.Unmarshal(ext.Value, &role); err != nil {
+			return "", fmt.Errorf("invalid role extension: %w", err)
+		}
+		return role, nil
+	}
+	return "", nil
+}
+
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	default:
+		return fmt.Sprintf("TLS 0x%04X", v)
+	}
+}
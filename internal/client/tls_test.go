@@ -0,0 +1,50 @@
+// internal/client/tls_test.go
+package client
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"testing"
+)
+
+func TestTLSOptions_Validate(t *testing.T) {
+	bad := []TLSOptions{
+		{CertFile: "client.pem"},
+		{KeyFile: "client-key.pem"},
+		{MinVersion: "1.1"},
+	}
+	for _, o := range bad {
+		if err := o.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", o)
+		}
+	}
+	if err := (TLSOptions{CertFile: "c.pem", KeyFile: "k.pem", MinVersion: "1.3"}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCertRole(t *testing.T) {
+	v, err := asn1.MarshalWithParams("Engineer", "utf8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := &x509.Certificate{Extensions: []pkix.Extension{
+		{Id: asn1.ObjectIdentifier{2, 5, 29, 15}, Value: []byte{3, 2, 7, 128}},
+		{Id: RoleOID, Value: v},
+	}}
+
+	role, err := CertRole(cert)
+	if err != nil || role != "Engineer" {
+		t.Fatalf("got %q, %v", role, err)
+	}
+
+	if role, err := CertRole(&x509.Certificate{}); err != nil || role != "" {
+		t.Fatalf("no extension: got %q, %v", role, err)
+	}
+
+	cert.Extensions[1].Value = []byte{0x30, 0x00}
+	if _, err := CertRole(cert); err == nil {
+		t.Fatal("expected error for a malformed role")
+	}
+}
@@ -0,0 +1,115 @@
+// internal/client/transport.go
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+)
+
+// Framing selects how request and response PDUs are wrapped on the wire.
+type Framing int
+
+const (
+	// FramingTCP is Modbus TCP: MBAP header with transaction ids.
+	FramingTCP Framing = iota
+	// FramingRTU is [Address][PDU][CRC16], delimited by silent intervals.
+	FramingRTU
+	// FramingASCII is ":" + hex([Address][PDU][LRC]) + CRLF.
+	FramingASCII
+)
+
+func (f Framing) String() string {
+	switch f {
+	case FramingRTU:
+		return "rtu"
+	case FramingASCII:
+		return "ascii"
+	default:
+		return "tcp"
+	}
+}
+
+// Transport describes how to reach a device: the link (a TCP or TLS
+// socket to the engine's target address, a UDP socket, or a serial port) and
+// the framing used on it. The zero value is Modbus TCP; FramingRTU or
+// FramingASCII without Serial tunnels those frames over TCP, as spoken
+// by serial-to-Ethernet converters.
+type Transport struct {
+	Framing Framing
+
+	// Serial, when set, replaces the TCP socket with a serial port.
+	Serial *SerialConfig
+
+	// UDP, when set, sends each MBAP frame as one datagram to the
+	// target instead of over a TCP stream. Retries is how many times
+	// a request is resent after a timeout.
+	UDP     bool
+	Retries int
+
+	// TLS, when set, wraps the TCP socket in TLS (Modbus/TCP Security).
+	TLS *tls.Config
+}
+
+// Open connects to a device over t. address is the TCP or UDP target
+// and is ignored for serial links.
+func Open(ctx context.Context, address string, t Transport, timeout time.Duration) (*Connection, error) {
+	var c *Connection
+	var err error
+
+	switch {
+	case t.Serial != nil:
+		c, err = OpenSerial(*t.Serial, timeout)
+	case t.UDP:
+		c, err = DialUDPContext(ctx, address, timeout)
+		if err == nil {
+			c.retries = t.Retries
+		}
+	case t.TLS != nil:
+		c, err = DialTLSContext(ctx, address, t.TLS, timeout)
+	default:
+		c, err = DialContext(ctx, address, timeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.framing = t.Framing
+	if t.Serial != nil && t.Framing == FramingRTU {
+		c.silence = RTUSilence(t.Serial.Baud)
+	}
+	return c, nil
+}
+
+// SerialConfig describes a serial line. Modbus defaults to 19200 8E1
+// for RTU and 7E1 for ASCII.
+type SerialConfig struct {
+	Device   string
+	Baud     int
+	DataBits int  // 7 or 8
+	Parity   byte // 'N', 'E' or 'O'
+	StopBits int  // 1 or 2
+}
+
+// Validate checks the line settings.
+func (s SerialConfig) Validate() error {
+	if s.Device == "" {
+		return fmt.Errorf("serial device required")
+	}
+	if s.Baud <= 0 {
+		return fmt.Errorf("baud must be > 0")
+	}
+	if s.DataBits != 7 && s.DataBits != 8 {
+		return fmt.Errorf("data bits must be 7 or 8")
+	}
+	switch s.Parity {
+	case 'N', 'E', 'O':
+	default:
+		return fmt.Errorf("parity must be N, E or O")
+	}
+	if s.StopBits != 1 && s.StopBits != 2 {
+		return fmt.Errorf("stop bits must be 1 or 2")
+	}
+	return nil
+}
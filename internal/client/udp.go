@@ -0,0 +1,63 @@
+// internal/client/udp.go
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DefaultUDPRetries is how many times a UDP request is resent after a
+// timeout when no other count is configured.
+const DefaultUDPRetries = 2
+
+// DialUDPContext opens a connected UDP socket to the Modbus target.
+// Each Write sends one datagram; use ReadDatagram to receive.
+func DialUDPContext(ctx context.Context, address string, timeout time.Duration) (*Connection, error) {
+	dialer := net.Dialer{Timeout: timeout}
+
+	c, err := dialer.DialContext(ctx, "udp", address)
+	if err != nil {
+		return nil, fmt.Errorf("dial failed: %w", err)
+	}
+
+	return &Connection{
+		conn:     c,
+		timeout:  timeout,
+		datagram: true,
+	}, nil
+}
+
+// ReadDatagram reads one datagram into b and returns its length.
+// A datagram longer than b is truncated.
+func (c *Connection) ReadDatagram(b []byte) (int, error) {
+	return c.ReadDatagramUntil(b, time.Now().Add(c.timeout))
+}
+
+// ReadDatagramUntil reads one datagram into b before deadline.
+func (c *Connection) ReadDatagramUntil(b []byte, deadline time.Time) (int, error) {
+	if err := c.conn.SetReadDeadline(deadline); err != nil {
+		return 0, err
+	}
+	return c.conn.Read(b)
+}
+
+// ParseDatagram splits one MBAP datagram into its transaction id and
+// frame body [UnitID][FC][Data...]. A datagram carries exactly one
+// ADU, so the MBAP length must cover the rest of it.
+func ParseDatagram(d []byte) (uint16, []byte, error) {
+	if len(d) < 8 {
+		return 0, nil, fmt.Errorf("datagram too short: %d bytes", len(d))
+	}
+
+	txID := binary.BigEndian.Uint16(d[0:2])
+	if binary.BigEndian.Uint16(d[2:4]) != 0 {
+		return txID, nil, fmt.Errorf("invalid protocol id")
+	}
+	if length := int(binary.BigEndian.Uint16(d[4:6])); length != len(d)-6 {
+		return txID, nil, fmt.Errorf("mbap length %d does not match datagram body of %d bytes", length, len(d)-6)
+	}
+	return txID, d[6:], nil
+}
@@ -4,15 +4,19 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
 )
 
 type Config struct {
 	TargetAddr string
 
-	Workers int
-	Rate    int
+	Workers  int
+	Rate     int
 	Duration time.Duration
 
 	RampRates    []int
@@ -22,16 +26,106 @@ type Config struct {
 	FunctionCode uint8
 	Address      uint16
 	Quantity     uint16
+	Value        uint16
+	SubFunction  uint16
+	Values       []uint16
+	ValuesFile   string
+	WriteAddress uint16
+	AndMask      uint16
+	OrMask       uint16
+	Records      []client.FileRecord
+	DeviceIDCode uint8
+	ObjectID     uint8
+
+	// records is the -records flag, kept to split values read later
+	// from ValuesFile over the FC 21 records.
+	records string
+
+	// Type interprets registers read with FC 3, 4 and 23; Order is the
+	// byte order of multi-register types. GuessOrder ("-byte-order
+	// auto") shows all four orders side by side instead.
+	Type       format.Type
+	Order      format.Order
+	GuessOrder bool
+
+	// Bits names bits of -type bitfield registers, shown as columns.
+	Bits []format.BitName
+
+	// Scaling turns typed values into engineering values with a unit.
+	// ScaleRegister, when not negative, is the address of a SunSpec
+	// scale register holding a power-of-ten exponent.
+	Scaling       format.Scaling
+	ScaleRegister int
+
+	// MapFile is a register map (-map); Points names the points to
+	// read (-point), or every readable point when empty.
+	MapFile string
+	Points  []string
+
+	// MaxGap, MaxQuantity and Adaptive tune how map points are batched
+	// into reads; see internal/planner.
+	MaxGap      int
+	MaxQuantity int
+	Adaptive    bool
 
 	Timeout time.Duration
 	Strict  bool
 	Quiet   bool
+
+	// Output is how results are written: "table" for people, "json"
+	// or "ndjson" (one object per line) for scripts, or "none" when
+	// only the CSV log is wanted. Poll repeats the read every interval
+	// until interrupted; 0 reads once.
+	Output string
+	Poll   time.Duration
+
+	// CSVFile logs every poll sample to a CSV file (-csv), rotated
+	// before it grows past CSVMaxSize bytes or once it is CSVMaxAge
+	// old (0 = never); CSVGzip compresses rotated files.
+	CSVFile    string
+	CSVMaxSize int64
+	CSVMaxAge  time.Duration
+	CSVGzip    bool
+
+	// MetricsAddr serves Prometheus metrics (-metrics-addr) while a
+	// poll or stress run lasts.
+	MetricsAddr string
+
+	// ClearCounters makes the diag command clear the device counters
+	// (FC 8 sub-function 0x0A) after reading them.
+	ClearCounters bool
+
+	// Transport is "tcp" (Modbus TCP to TargetAddr), "tls" (Modbus/TCP
+	// Security), "udp" (MBAP datagrams to TargetAddr), "rtu" or "ascii"
+	// (on the Serial line), or "rtu-over-tcp" or "ascii-over-tcp"
+	// (those frames on a TCP socket to TargetAddr, as forwarded by
+	// serial converters).
+	Transport string
+	Serial    client.SerialConfig
+
+	// Retries is how many times a UDP request is resent after a timeout.
+	Retries int
+
+	// TLS holds the -tls-* flags for -transport tls.
+	TLS client.TLSOptions
+
+	// ConnMode selects how the engine manages TCP connections:
+	// "dial" (connect per request), "persistent", "pool" or "pipeline".
+	ConnMode            string
+	Connections         int
+	PipelineDepth       int
+	ReconnectBackoff    time.Duration
+	ReconnectMaxBackoff time.Duration
+
+	// Stress is set when any load flag (-workers, -rate, -duration,
+	// -ramp, -step-duration) was given explicitly.
+	Stress bool
 }
 
 func Parse() *Config {
 	cfg := &Config{}
 
-	flag.StringVar(&cfg.TargetAddr, "target", "127.0.0.1:502", "Modbus TCP target address")
+	flag.StringVar(&cfg.TargetAddr, "target", "127.0.0.1:502", "Modbus TCP target address (port 802 by default for -transport tls)")
 
 	flag.IntVar(&cfg.Workers, "workers", 10, "Number of concurrent workers")
 	flag.IntVar(&cfg.Rate, "rate", 0, "Requests per second (0 = unlimited)")
@@ -44,17 +138,173 @@ func Parse() *Config {
 	fc := flag.Int("fc", 3, "Modbus function code")
 	addr := flag.Int("address", 0, "Starting register address")
 	qty := flag.Int("quantity", 10, "Number of registers")
+	value := flag.Int("value", 0, "Value for FC 5 (0=OFF, non-zero=ON) and FC 6, data word for FC 8")
+	subFunc := flag.String("sub-function", "0", "FC 8 diagnostic sub-function, e.g. 0x0B")
+	values := flag.String("values", "", "Comma-separated values for FC 15, 16, 21 and 23, e.g. 1,0,1 or 100,0x1F")
+	flag.StringVar(&cfg.ValuesFile, "values-file", "", "File with values for FC 15, 16, 21 and 23 (comma, space or newline separated)")
+	writeAddr := flag.Int("write-address", 0, "Write start address for FC 23")
+	andMask := flag.String("and-mask", "0xFFFF", "AND mask for FC 22")
+	orMask := flag.String("or-mask", "0x0000", "OR mask for FC 22")
+	records := flag.String("records", "", "FC 20/21 file records as file:record[:length], e.g. 4:1:10,4:20:5")
+	idCode := flag.Int("device-id-code", 1, "FC 43 access: 1=basic, 2=regular, 3=extended, 4=individual")
+	objectID := flag.Int("object-id", 0, "First FC 43 object id (the only one for -device-id-code 4)")
+	typ := flag.String("type", "uint16", "Register type for FC 3, 4 and 23: "+strings.Join(format.TypeNames(), ", "))
+	order := flag.String("byte-order", "ABCD", "Byte order of 32/64-bit types: ABCD, CDAB, BADC, DCBA, or auto to compare all four; BADC byte-swaps strings")
+	bits := flag.String("bits", "", "Named bits for -type bitfield, e.g. 0:running,1:fault,15:alarm")
+	flag.Float64Var(&cfg.Scaling.Scale, "scale", 1, "Multiply read values by this factor")
+	flag.Float64Var(&cfg.Scaling.Offset, "offset", 0, "Add this offset after scaling")
+	flag.StringVar(&cfg.Scaling.Unit, "eng-unit", "", "Engineering unit shown with scaled values, e.g. V or kWh")
+	flag.StringVar(&cfg.MapFile, "map", "", "Register map file (.yaml, .json or .csv) to read named points from")
+	points := flag.String("point", "", "Comma-separated points to read from -map (default: all readable points)")
+	flag.IntVar(&cfg.MaxGap, "max-gap", 0, "Unwanted registers (or bits) read to join -map points into one request")
+	flag.IntVar(&cfg.MaxQuantity, "max-quantity", 0, "Largest -map read quantity (0 = protocol limit: 125 registers, 2000 bits)")
+	flag.BoolVar(&cfg.Adaptive, "adaptive", false, "Split -map reads the device rejects (exception 02/03) and remember the split")
+	flag.IntVar(&cfg.ScaleRegister, "scale-register", -1, "Address of a SunSpec scale register (power-of-ten exponent) read with the values (-1 = none)")
 
 	flag.DurationVar(&cfg.Timeout, "timeout", 100*time.Millisecond, "Socket timeout")
 	flag.BoolVar(&cfg.Strict, "strict", false, "Strict Modbus TCP framing")
 	flag.BoolVar(&cfg.Quiet, "quiet", false, "Minimal output")
+	flag.StringVar(&cfg.Output, "output", "table", "Output format: table, json, ndjson (one JSON object per line) or none (with -csv)")
+	flag.DurationVar(&cfg.Poll, "poll", 0, "Repeat the read every interval until interrupted, e.g. 1s (0 = read once)")
+	flag.StringVar(&cfg.CSVFile, "csv", "", "Log -poll samples to this CSV file")
+	csvSize := flag.String("csv-max-size", "0", "Rotate the -csv file before it grows past this size, e.g. 10MB (0 = never)")
+	flag.DurationVar(&cfg.CSVMaxAge, "csv-max-age", 0, "Rotate the -csv file after this long, e.g. 24h (0 = never)")
+	flag.BoolVar(&cfg.CSVGzip, "csv-gzip", false, "Gzip rotated -csv files")
+	flag.StringVar(&cfg.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics at http://ADDR/metrics during -poll or stress runs, e.g. :9102")
+	flag.BoolVar(&cfg.ClearCounters, "clear-counters", false, "diag: clear device counters after reading them")
+
+	flag.StringVar(&cfg.Transport, "transport", "tcp", "Transport: tcp, tls, udp, rtu, ascii (serial, need -device), rtu-over-tcp or ascii-over-tcp")
+	flag.StringVar(&cfg.Serial.Device, "device", "", "Serial device for -transport rtu or ascii, e.g. /dev/ttyUSB0")
+	flag.IntVar(&cfg.Serial.Baud, "baud", 19200, "Serial baud rate")
+	parity := flag.String("parity", "E", "Serial parity: N, E or O")
+	flag.IntVar(&cfg.Serial.DataBits, "data-bits", 8, "Serial data bits: 7 or 8 (ascii defaults to 7)")
+	flag.IntVar(&cfg.Serial.StopBits, "stop-bits", 1, "Serial stop bits: 1 or 2")
+	flag.IntVar(&cfg.Retries, "retries", client.DefaultUDPRetries, "UDP retransmissions after a timeout")
+	flag.StringVar(&cfg.TLS.CAFile, "tls-ca", "", "CA bundle (PEM) that verifies the server for -transport tls (default: system roots)")
+	flag.StringVar(&cfg.TLS.CertFile, "tls-cert", "", "Client certificate (PEM) for -transport tls")
+	flag.StringVar(&cfg.TLS.KeyFile, "tls-key", "", "Client private key (PEM) for -tls-cert")
+	flag.StringVar(&cfg.TLS.ServerName, "tls-server-name", "", "Server name to verify (default: the -target host)")
+	flag.StringVar(&cfg.TLS.MinVersion, "tls-min-version", "1.2", "Minimum TLS version: 1.2 or 1.3")
+
+	flag.StringVar(&cfg.ConnMode, "conn-mode", "dial", "Connection mode: dial (per request), persistent, pool or pipeline")
+	flag.IntVar(&cfg.PipelineDepth, "pipeline-depth", 8, "Max outstanding transactions for -conn-mode pipeline")
+	flag.IntVar(&cfg.Connections, "connections", 0, "Pool size for -conn-mode pool (0 = one per worker)")
+	flag.DurationVar(&cfg.ReconnectBackoff, "reconnect-backoff", 100*time.Millisecond, "Initial redial delay after a failed dial")
+	flag.DurationVar(&cfg.ReconnectMaxBackoff, "reconnect-max-backoff", 5*time.Second, "Maximum redial delay")
 
 	flag.Parse()
 
+	connModeSet, dataBitsSet, targetSet := false, false, false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "target":
+			targetSet = true
+		case "workers", "rate", "duration", "ramp", "step-duration":
+			cfg.Stress = true
+		case "conn-mode":
+			connModeSet = true
+		case "data-bits":
+			dataBitsSet = true
+		}
+	})
+
+	if len(*parity) == 1 {
+		cfg.Serial.Parity = strings.ToUpper(*parity)[0]
+	}
+
+	// A serial line is opened once, not per request; a UDP socket is
+	// kept so late responses can be recognized.
+	if (cfg.Transport == "rtu" || cfg.Transport == "ascii" || cfg.Transport == "udp") && !connModeSet {
+		cfg.ConnMode = "persistent"
+	}
+	// Modbus/TCP Security listens on its own port.
+	if cfg.Transport == "tls" && !targetSet {
+		cfg.TargetAddr = fmt.Sprintf("127.0.0.1:%d", client.SecurePort)
+	}
+	// Modbus ASCII lines default to 7 data bits.
+	if cfg.Transport == "ascii" && !dataBitsSet {
+		cfg.Serial.DataBits = 7
+	}
+
 	cfg.UnitID = uint8(*unit)
 	cfg.FunctionCode = uint8(*fc)
 	cfg.Address = uint16(*addr)
 	cfg.Quantity = uint16(*qty)
+	cfg.Value = uint16(*value)
+
+	if *value < 0 || *value > 0xFFFF {
+		fmt.Fprintf(os.Stderr, "config error: value must be 0..65535\n")
+		os.Exit(1)
+	}
+
+	if *writeAddr < 0 || *writeAddr > 0xFFFF {
+		fmt.Fprintf(os.Stderr, "config error: write-address must be 0..65535\n")
+		os.Exit(1)
+	}
+
+	cfg.WriteAddress = uint16(*writeAddr)
+	if *idCode < 1 || *idCode > 4 {
+		fmt.Fprintf(os.Stderr, "config error: device-id-code must be 1..4\n")
+		os.Exit(1)
+	}
+	if *objectID < 0 || *objectID > 0xFF {
+		fmt.Fprintf(os.Stderr, "config error: object-id must be 0..255\n")
+		os.Exit(1)
+	}
+
+	cfg.DeviceIDCode = uint8(*idCode)
+	cfg.ObjectID = uint8(*objectID)
+
+	sub, err := ParseValues(*subFunc)
+	if err != nil || len(sub) != 1 {
+		fmt.Fprintf(os.Stderr, "config error: invalid sub-function %q\n", *subFunc)
+		os.Exit(1)
+	}
+	cfg.SubFunction = sub[0]
+
+	if cfg.Type, err = format.ParseType(*typ); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+	if *bits != "" {
+		if cfg.Bits, err = format.ParseBitLayout(*bits); err != nil {
+			fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if strings.EqualFold(*order, "auto") {
+		cfg.GuessOrder = true
+	} else if cfg.Order, err = format.ParseOrder(*order); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+		os.Exit(1)
+	}
+
+	masks, err := ParseValues(*andMask + "," + *orMask)
+	if err != nil || len(masks) != 2 {
+		fmt.Fprintf(os.Stderr, "config error: invalid mask: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.AndMask, cfg.OrMask = masks[0], masks[1]
+
+	// Values in a -values-file are read by the caller; see SetValues.
+	cfg.records = *records
+	if cfg.ValuesFile == "" {
+		if err := cfg.applyValues(*values); err != nil {
+			fmt.Fprintf(os.Stderr, "config error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	for _, p := range strings.Split(*points, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			cfg.Points = append(cfg.Points, p)
+		}
+	}
+
+	if cfg.CSVMaxSize, err = ParseSize(*csvSize); err != nil {
+		fmt.Fprintf(os.Stderr, "config error: csv-max-size: %v\n", err)
+		os.Exit(1)
+	}
 
 	if *ramp != "" {
 		parts := strings.Split(*ramp, ",")
@@ -75,6 +325,47 @@ func Parse() *Config {
 	return cfg
 }
 
+// SetValues sets the write values from list, the contents of
+// ValuesFile, and validates the configuration again.
+func (c *Config) SetValues(list string) error {
+	if err := c.applyValues(list); err != nil {
+		return err
+	}
+	return c.validate()
+}
+
+// applyValues parses the write values in list and what depends on
+// them: the FC 20/21 records and the FC 15/16 quantity.
+func (c *Config) applyValues(list string) error {
+	v, err := ParseValues(list)
+	if err != nil {
+		return err
+	}
+	c.Values = v
+
+	if c.records != "" {
+		var data []uint16
+		if c.FunctionCode == 21 {
+			data = c.Values
+		}
+		if c.Records, err = ParseRecords(c.records, data); err != nil {
+			return err
+		}
+	}
+
+	// Multiple writes write exactly the values given.
+	if c.FunctionCode == 15 || c.FunctionCode == 16 {
+		c.Quantity = uint16(len(c.Values))
+	}
+	return nil
+}
+
+// valuesPending reports whether the write values are still to be read
+// from ValuesFile.
+func (c *Config) valuesPending() bool {
+	return c.ValuesFile != "" && c.Values == nil
+}
+
 func (c *Config) validate() error {
 	if c.TargetAddr == "" {
 		return fmt.Errorf("target required")
@@ -82,15 +373,314 @@ func (c *Config) validate() error {
 	if c.Workers <= 0 {
 		return fmt.Errorf("workers must be > 0")
 	}
-	if c.Quantity == 0 {
+	switch c.FunctionCode {
+	case 1, 2, 3, 4, 5, 6, 7, 8, 11, 12, 15, 16, 17, 20, 21, 22, 23, 24, 43:
+	default:
+		return fmt.Errorf("unsupported function code: %d", c.FunctionCode)
+	}
+	if c.ValuesFile != "" {
+		switch c.FunctionCode {
+		case 15, 16, 21, 23:
+		default:
+			return fmt.Errorf("values-file needs fc 15, 16, 21 or 23")
+		}
+	}
+	if (c.FunctionCode == 20 || c.FunctionCode == 21) && len(c.Records) == 0 && (c.records == "" || !c.valuesPending()) {
+		return fmt.Errorf("fc %d requires -records", c.FunctionCode)
+	}
+	if c.FunctionCode == 43 && (c.DeviceIDCode < 1 || c.DeviceIDCode > 4) {
+		return fmt.Errorf("device-id-code must be 1..4")
+	}
+	switch c.FunctionCode {
+	case 15, 16, 21, 23:
+		if len(c.Values) == 0 && !c.valuesPending() {
+			return fmt.Errorf("fc %d requires -values or -values-file", c.FunctionCode)
+		}
+	}
+	if c.Quantity == 0 && !c.valuesPending() {
 		return fmt.Errorf("quantity must be > 0")
 	}
+	if c.Rate < 0 {
+		return fmt.Errorf("rate must be >= 0")
+	}
+	if c.Stress && len(c.RampRates) == 0 && c.Duration <= 0 {
+		return fmt.Errorf("duration must be > 0")
+	}
 	if len(c.RampRates) > 0 && c.StepDuration <= 0 {
 		return fmt.Errorf("step-duration must be > 0")
 	}
+	switch c.ConnMode {
+	case "dial", "persistent", "pool", "pipeline":
+	default:
+		return fmt.Errorf("conn-mode must be dial, persistent, pool or pipeline")
+	}
+	switch c.Transport {
+	case "tcp":
+	case "tls":
+		if c.ConnMode == "pipeline" {
+			return fmt.Errorf("conn-mode pipeline needs -transport tcp")
+		}
+		if err := c.TLS.Validate(); err != nil {
+			return err
+		}
+	case "udp":
+		if c.ConnMode == "pipeline" {
+			return fmt.Errorf("conn-mode pipeline needs -transport tcp")
+		}
+		if c.Retries < 0 {
+			return fmt.Errorf("retries must be >= 0")
+		}
+	case "rtu", "ascii":
+		if err := c.Serial.Validate(); err != nil {
+			return err
+		}
+		if c.ConnMode == "pool" || c.ConnMode == "pipeline" {
+			return fmt.Errorf("conn-mode %s needs -transport tcp; a serial line carries one request at a time", c.ConnMode)
+		}
+	case "rtu-over-tcp", "ascii-over-tcp":
+		if c.ConnMode == "pipeline" {
+			return fmt.Errorf("conn-mode pipeline needs -transport tcp; %s frames carry no transaction id", c.Transport)
+		}
+	default:
+		return fmt.Errorf("transport must be tcp, tls, udp, rtu, ascii, rtu-over-tcp or ascii-over-tcp")
+	}
+	if c.Type != format.TypeUint16 {
+		switch c.FunctionCode {
+		case 3, 4, 23:
+		default:
+			return fmt.Errorf("type %s needs fc 3, 4 or 23", c.Type)
+		}
+		if n := c.Type.Registers(); n > 1 && int(c.Quantity)%n != 0 {
+			return fmt.Errorf("quantity must be a multiple of %d for type %s", n, c.Type)
+		}
+	}
+	switch {
+	case c.GuessOrder && (!c.Type.Numeric() || c.Type.Registers() == 1):
+		return fmt.Errorf("byte-order auto needs a 32- or 64-bit numeric -type")
+	case c.Type == format.TypeString:
+		if c.Order != format.OrderABCD && c.Order != format.OrderBADC {
+			return fmt.Errorf("type string takes byte-order ABCD or BADC (byte swap)")
+		}
+	case c.Order != format.OrderABCD && c.Type.Registers() == 1:
+		return fmt.Errorf("byte-order needs a 32- or 64-bit -type")
+	}
+	if len(c.Bits) > 0 && c.Type != format.TypeBitfield {
+		return fmt.Errorf("bits needs -type bitfield")
+	}
+	if c.MapFile == "" && len(c.Points) > 0 {
+		return fmt.Errorf("point needs -map")
+	}
+	switch c.Output {
+	case "table":
+	case "json", "ndjson":
+		if c.Stress {
+			return fmt.Errorf("output %s is not supported for stress runs", c.Output)
+		}
+	case "none":
+		if c.CSVFile == "" {
+			return fmt.Errorf("output none needs -csv")
+		}
+	default:
+		return fmt.Errorf("output must be table, json, ndjson or none")
+	}
+	if c.Poll < 0 {
+		return fmt.Errorf("poll must be >= 0")
+	}
+	if c.Poll > 0 {
+		switch {
+		case c.Stress:
+			return fmt.Errorf("poll cannot be combined with stress flags")
+		case c.MapFile == "" && (c.FunctionCode < 1 || c.FunctionCode > 4):
+			return fmt.Errorf("poll needs fc 1-4 or -map")
+		}
+	}
+	if c.CSVFile != "" && c.Poll == 0 {
+		return fmt.Errorf("csv needs -poll")
+	}
+	if c.CSVFile == "" && (c.CSVMaxSize != 0 || c.CSVMaxAge != 0 || c.CSVGzip) {
+		return fmt.Errorf("csv-max-size, csv-max-age and csv-gzip need -csv")
+	}
+	if c.CSVMaxAge < 0 {
+		return fmt.Errorf("csv-max-age must be >= 0")
+	}
+	if c.CSVGzip && c.CSVMaxSize == 0 && c.CSVMaxAge == 0 {
+		return fmt.Errorf("csv-gzip needs -csv-max-size or -csv-max-age")
+	}
+	if c.MetricsAddr != "" && c.Poll == 0 && !c.Stress {
+		return fmt.Errorf("metrics-addr needs -poll or stress flags")
+	}
+	if c.MapFile != "" && c.Stress {
+		return fmt.Errorf("map reads cannot be combined with stress flags")
+	}
+	if c.MaxGap < 0 || c.MaxGap > 0xFFFF {
+		return fmt.Errorf("max-gap must be 0-65535")
+	}
+	if c.MaxQuantity < 0 || c.MaxQuantity > 2000 {
+		return fmt.Errorf("max-quantity must be 0-2000")
+	}
+	if c.Scaling.Scale == 0 {
+		return fmt.Errorf("scale must not be 0")
+	}
+	if !c.Scaling.IsZero() || c.ScaleRegister >= 0 {
+		switch c.FunctionCode {
+		case 3, 4, 23:
+		default:
+			return fmt.Errorf("scaling needs fc 3, 4 or 23")
+		}
+		switch {
+		case !c.Type.Numeric() && c.Type != format.TypeBCD16 && c.Type != format.TypeBCD32:
+			return fmt.Errorf("type %s cannot be scaled", c.Type)
+		case c.GuessOrder:
+			return fmt.Errorf("scaling cannot be combined with byte-order auto")
+		case c.ScaleRegister > 0xFFFF:
+			return fmt.Errorf("scale-register must be 0-65535")
+		}
+	}
+	if c.PipelineDepth <= 0 {
+		return fmt.Errorf("pipeline-depth must be > 0")
+	}
+	if c.Connections < 0 {
+		return fmt.Errorf("connections must be >= 0")
+	}
+	if c.ReconnectBackoff < 0 || c.ReconnectMaxBackoff < c.ReconnectBackoff {
+		return fmt.Errorf("reconnect-max-backoff must be >= reconnect-backoff >= 0")
+	}
 	return nil
 }
 
+// ParseSize parses a byte size: a plain number of bytes, or one with a
+// K, M or G suffix (powers of 1024), optionally followed by B or iB,
+// e.g. "512K", "10MB", "1GiB".
+func ParseSize(s string) (int64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	t = strings.TrimSuffix(strings.TrimSuffix(t, "B"), "I")
+
+	mult := int64(1)
+	if n := len(t); n > 0 {
+		switch t[n-1] {
+		case 'K':
+			mult = 1 << 10
+		case 'M':
+			mult = 1 << 20
+		case 'G':
+			mult = 1 << 30
+		}
+		if mult > 1 {
+			t = t[:n-1]
+		}
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(t), 10, 64)
+	if err != nil || n < 0 || n > (1<<62)/mult {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * mult, nil
+}
+
+// ParseValues parses a list of 16-bit values separated by commas,
+// spaces or newlines. Decimal, 0x hex and 0b binary are accepted.
+func ParseValues(s string) ([]uint16, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	out := make([]uint16, 0, len(fields))
+	for _, f := range fields {
+		v, err := strconv.ParseUint(f, 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value %q: must be 0..65535", f)
+		}
+		out = append(out, uint16(v))
+	}
+	return out, nil
+}
+
+// ParseRecords parses FC 20/21 file records "file:record[:length]"
+// separated by commas or spaces. When values is non-empty (FC 21) it
+// is split over the records in order: every record takes length
+// values, and a single record without a length takes them all.
+func ParseRecords(s string, values []uint16) ([]client.FileRecord, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+
+	out := make([]client.FileRecord, 0, len(fields))
+	for _, f := range fields {
+		parts := strings.Split(f, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid record %q: want file:record[:length]", f)
+		}
+
+		nums, err := ParseValues(strings.Join(parts, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid record %q: %v", f, err)
+		}
+
+		rec := client.FileRecord{File: nums[0], Record: nums[1]}
+		if len(nums) == 3 {
+			rec.Length = nums[2]
+		}
+		out = append(out, rec)
+	}
+
+	if len(values) == 0 {
+		for _, rec := range out {
+			if rec.Length == 0 {
+				return nil, fmt.Errorf("record %d:%d needs a length", rec.File, rec.Record)
+			}
+		}
+		return out, nil
+	}
+
+	if len(out) == 1 && out[0].Length == 0 {
+		out[0].Length = uint16(len(values))
+	}
+	rest := values
+	for i := range out {
+		n := int(out[i].Length)
+		if n == 0 || n > len(rest) {
+			return nil, fmt.Errorf("record %d:%d: length %d does not match the %d values left", out[i].File, out[i].Record, n, len(rest))
+		}
+		out[i].Data = rest[:n]
+		rest = rest[n:]
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%d values left over after the last record", len(rest))
+	}
+	return out, nil
+}
+
+// ParseSerialTarget parses a serial target "/dev/ttyUSB0", optionally
+// followed by "@baud" and ",8E1"-style line settings. ok is false when
+// s is not a serial device path.
+func ParseSerialTarget(s string) (cfg client.SerialConfig, ok bool, err error) {
+	if !strings.HasPrefix(s, "/dev/") {
+		return client.SerialConfig{}, false, nil
+	}
+
+	cfg = client.SerialConfig{Baud: 19200, DataBits: 8, Parity: 'E', StopBits: 1}
+
+	dev, opts, _ := strings.Cut(s, "@")
+	cfg.Device = dev
+
+	if opts != "" {
+		baud, line, _ := strings.Cut(opts, ",")
+		if cfg.Baud, err = strconv.Atoi(baud); err != nil {
+			return cfg, true, fmt.Errorf("invalid baud rate %q", baud)
+		}
+		if line != "" {
+			if len(line) != 3 {
+				return cfg, true, fmt.Errorf("invalid line settings %q: want e.g. 8E1", line)
+			}
+			cfg.DataBits = int(line[0] - '0')
+			cfg.Parity = strings.ToUpper(line)[1]
+			cfg.StopBits = int(line[2] - '0')
+		}
+	}
+
+	return cfg, true, cfg.Validate()
+}
+
 // EngineReadConfig is consumed by the read engine.
 // It must remain pure data (no flags, no os.Exit, no I/O).
 type EngineReadConfig struct {
@@ -101,7 +691,6 @@ type EngineReadConfig struct {
 	Quantity     uint16
 	Timeout      time.Duration
 	Strict       bool
-
 }
 
 // ToEngineRead converts CLI/test configuration into engine-safe config.
@@ -114,6 +703,5 @@ func (c *Config) ToEngineRead() EngineReadConfig {
 		Quantity:     c.Quantity,
 		Timeout:      c.Timeout,
 		Strict:       c.Strict,
-
 	}
 }
@@ -0,0 +1,116 @@
+// internal/config/config_test.go
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestParseValues(t *testing.T) {
+	got, err := ParseValues("1, 0x1F,0b101\n65535\t7\r\n")
+	if err != nil {
+		t.Fatalf("ParseValues error: %v", err)
+	}
+
+	want := []uint16{1, 0x1F, 5, 65535, 7}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestParseValues_RejectsOutOfRange(t *testing.T) {
+	for _, in := range []string{"65536", "-1", "abc"} {
+		if _, err := ParseValues(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
+
+func TestSetValues_FromValuesFile(t *testing.T) {
+	cfg := &Config{
+		TargetAddr:    "127.0.0.1:502",
+		Workers:       1,
+		FunctionCode:  21,
+		Quantity:      10,
+		ValuesFile:    "values.txt",
+		records:       "4:1:2,4:20:1",
+		ConnMode:      "dial",
+		Transport:     "tcp",
+		Output:        "table",
+		Scaling:       format.Scaling{Scale: 1},
+		ScaleRegister: -1,
+		PipelineDepth: 1,
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate before the values are read: %v", err)
+	}
+	if err := cfg.SetValues("10 11\n12"); err != nil {
+		t.Fatalf("SetValues error: %v", err)
+	}
+	if len(cfg.Records) != 2 || !reflect.DeepEqual(cfg.Records[1].Data, []uint16{12}) {
+		t.Fatalf("unexpected records %+v", cfg.Records)
+	}
+
+	cfg.FunctionCode, cfg.records, cfg.Records = 16, "", nil
+	if err := cfg.SetValues(""); err == nil {
+		t.Fatal("expected an empty values file to fail fc 16")
+	}
+	if err := cfg.SetValues("1,2,3"); err != nil || cfg.Quantity != 3 {
+		t.Fatalf("quantity = %d (%v), want 3", cfg.Quantity, err)
+	}
+}
+
+func TestParseRecords_SplitsValues(t *testing.T) {
+	got, err := ParseRecords("4:1:2, 4:20:1", []uint16{10, 11, 12})
+	if err != nil {
+		t.Fatalf("ParseRecords error: %v", err)
+	}
+	if len(got) != 2 || !reflect.DeepEqual(got[0].Data, []uint16{10, 11}) || !reflect.DeepEqual(got[1].Data, []uint16{12}) {
+		t.Fatalf("unexpected records %+v", got)
+	}
+
+	got, err = ParseRecords("0x4:7", []uint16{1, 2, 3})
+	if err != nil || got[0].File != 4 || got[0].Length != 3 {
+		t.Fatalf("single record should take all values: %+v (%v)", got, err)
+	}
+}
+
+func TestParseRecords_Rejects(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		values []uint16
+	}{
+		{"4:1", nil},                    // read needs a length
+		{"4", nil},                      // missing record
+		{"4:1:2", []uint16{1, 2, 3}},    // values left over
+		{"4:1:2,4:5:2", []uint16{1, 2}}, // values run out
+	} {
+		if _, err := ParseRecords(tc.in, tc.values); err == nil {
+			t.Fatalf("expected error for %q %v", tc.in, tc.values)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	cases := map[string]int64{
+		"0":     0,
+		"1500":  1500,
+		"512K":  512 << 10,
+		"10MB":  10 << 20,
+		"10mib": 10 << 20,
+		"1G":    1 << 30,
+	}
+	for in, want := range cases {
+		got, err := ParseSize(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseSize(%q) = %d, %v want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "10XB", "M"} {
+		if _, err := ParseSize(in); err == nil {
+			t.Fatalf("expected error for %q", in)
+		}
+	}
+}
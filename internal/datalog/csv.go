@@ -0,0 +1,123 @@
+// internal/datalog/csv.go
+package datalog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+// Columns are the CSV header of every log file.
+var Columns = []string{"timestamp", "point", "address", "raw", "value", "unit", "latency_ms", "error"}
+
+// TimeFormat is the timestamp layout: local time with milliseconds
+// and the UTC offset, which spreadsheets and pandas both parse.
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// CSVLogger appends poll samples to a CSV file, one line per table
+// row, so that a session can be handed on as a spreadsheet.
+type CSVLogger struct {
+	f *File
+}
+
+// OpenCSV opens (or appends to) the CSV log at path.
+func OpenCSV(path string, rot Rotation) (*CSVLogger, error) {
+	var header bytes.Buffer
+	w := csv.NewWriter(&header)
+	w.Write(Columns)
+	w.Flush()
+
+	f, err := OpenFile(path, rot, header.Bytes(), recordTime)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVLogger{f: f}, nil
+}
+
+// recordTime reads the timestamp column of a logged line.
+func recordTime(record []byte) (time.Time, bool) {
+	ts, _, _ := bytes.Cut(record, []byte(","))
+	t, err := time.Parse(TimeFormat, string(ts))
+	return t, err == nil
+}
+
+// Log appends one line per row of out. A sample that failed without
+// rows is logged as one line carrying its error. All lines of a sample
+// go to the same file.
+func (l *CSVLogger) Log(out output.Output) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(Records(out)); err != nil {
+		return err
+	}
+	_, err := l.f.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the log file.
+func (l *CSVLogger) Close() error {
+	return l.f.Close()
+}
+
+// Records flattens out into CSV records under Columns. Rows are read
+// by their cell keys: point, address, raw, error, and value or scaled
+// (the engineering value, split into value and unit). A row without a
+// value logs its raw reading as the value.
+func Records(out output.Output) [][]string {
+	m := out.Meta
+	at := m.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+	stamp := at.Format(TimeFormat)
+	latency := strconv.FormatFloat(float64(m.Latency.Microseconds())/1000, 'f', 3, 64)
+
+	if out.Table == nil || len(out.Table.Rows) == 0 {
+		addr := ""
+		if m.Quantity > 0 {
+			addr = strconv.Itoa(int(m.Address))
+		}
+		return [][]string{{stamp, "", addr, "", "", "", latency, out.Error}}
+	}
+
+	records := make([][]string, 0, len(out.Table.Rows))
+	for _, r := range out.Table.Rows {
+		c := r.Cells
+		var value, unit string
+		if s, ok := c["scaled"].(format.Engineering); ok {
+			value, unit = strconv.FormatFloat(s.Value, 'g', -1, 64), s.Unit
+		} else if v, ok := c["value"]; ok {
+			value = cell(v)
+		} else {
+			value = cell(c["raw"])
+		}
+
+		records = append(records, []string{
+			stamp,
+			cell(c["point"]),
+			cell(c["address"]),
+			cell(c["raw"]),
+			value,
+			unit,
+			latency,
+			cell(c["error"]),
+		})
+	}
+	return records
+}
+
+func cell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case format.Bits:
+		// The word as a number, not the binary pattern it prints as.
+		return strconv.Itoa(int(v))
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,83 @@
+// internal/datalog/csv_test.go
+package datalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+var sampleTime = time.Date(2024, 5, 1, 10, 0, 0, 250e6, time.UTC)
+
+func TestRecords_Rows(t *testing.T) {
+	out := output.Output{
+		Meta: output.Meta{Timestamp: sampleTime, Latency: 1500 * time.Microsecond},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"address": 0, "raw": uint16(7)}},
+			{Cells: map[string]any{"address": 1, "raw": "0x0933", "value": int16(2355), "scaled": format.Engineering{Value: 235.5, Unit: "V"}}},
+			{Cells: map[string]any{"point": "pf", "address": 4, "raw": "0x0001", "value": float32(0.5)}},
+			{Cells: map[string]any{"point": "freq", "address": 9, "error": "modbus exception fc=3 code=2"}},
+			{Cells: map[string]any{"point": "status", "address": 12, "raw": "0x0105", "value": format.Bits(0x0105)}},
+		}},
+	}
+
+	want := [][]string{
+		{"2024-05-01T10:00:00.250Z", "", "0", "7", "7", "", "1.500", ""},
+		{"2024-05-01T10:00:00.250Z", "", "1", "0x0933", "235.5", "V", "1.500", ""},
+		{"2024-05-01T10:00:00.250Z", "pf", "4", "0x0001", "0.5", "", "1.500", ""},
+		{"2024-05-01T10:00:00.250Z", "freq", "9", "", "", "", "1.500", "modbus exception fc=3 code=2"},
+		{"2024-05-01T10:00:00.250Z", "status", "12", "0x0105", "261", "", "1.500", ""},
+	}
+	if got := Records(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestRecords_FailedSample(t *testing.T) {
+	out := output.Output{
+		Meta:  output.Meta{Timestamp: sampleTime, Address: 100, Quantity: 2},
+		Error: errors.New("i/o timeout").Error(),
+	}
+	want := [][]string{{"2024-05-01T10:00:00.250Z", "", "100", "", "", "", "0.000", "i/o timeout"}}
+	if got := Records(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
+}
+
+func TestOpenCSV_DatesAppendedLogByFirstRow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.csv")
+	out := output.Output{
+		Meta:  output.Meta{Timestamp: sampleTime},
+		Table: &output.Table{Rows: []output.Row{{Cells: map[string]any{"address": 0, "raw": uint16(7)}}}},
+	}
+
+	l, err := OpenCSV(path, Rotation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(out); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	// Reopened today, the log is far older than MaxAge by its first
+	// row even though it was just modified.
+	l, err = OpenCSV(path, Rotation{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Log(out); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "poll-20240501T100000Z.csv")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,310 @@
+// internal/datalog/rotate.go
+package datalog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rotation says when a log file is closed and a fresh one started.
+// The zero value never rotates.
+type Rotation struct {
+	// MaxSize rotates before a write would take the file past this
+	// many bytes; 0 means no size limit.
+	MaxSize int64
+
+	// MaxAge rotates once the file has been written this long; 0
+	// means no time limit.
+	MaxAge time.Duration
+
+	// Gzip compresses rotated files to name.gz in the background.
+	Gzip bool
+}
+
+// File is an append-only file that rotates by size or age. A rotated
+// file is renamed after the time it was started, e.g. poll.csv becomes
+// poll-20240501T100000Z.csv, and the next file starts with header.
+//
+// Every Write lands whole in one file, so writing one record per call
+// never splits a record across files. A File is not safe for
+// concurrent use.
+type File struct {
+	path   string
+	rot    Rotation
+	header []byte
+
+	f    *os.File
+	size int64
+
+	// started is when the file was created, or for a file appended
+	// to, the time of its first record: a restart does not reset its
+	// age.
+	started time.Time
+	start   func(record []byte) (time.Time, bool)
+
+	// gz tracks background compressions; gzErr holds the first one
+	// that failed until Write or Close reports it.
+	gz    sync.WaitGroup
+	mu    sync.Mutex
+	gzErr error
+
+	now func() time.Time
+}
+
+// OpenFile opens path for appending, writing header first when the
+// file is new or empty. A file that starts with another header, such
+// as a log written with other columns, is rotated first.
+//
+// start reads the time a record was written from one line of the
+// file; it dates an appended file by its first record after the
+// header. When start is nil or that record has no readable time, the
+// file's modification time is used instead.
+func OpenFile(path string, rot Rotation, header []byte, start func(record []byte) (time.Time, bool)) (*File, error) {
+	f := &File{path: path, rot: rot, header: header, start: start, now: time.Now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	if f.size > 0 && len(header) > 0 {
+		ok, err := f.hasHeader()
+		if err == nil && !ok {
+			err = f.rotate()
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// hasHeader reports whether the file at f.path starts with f.header.
+func (f *File) hasHeader() (bool, error) {
+	fh, err := os.Open(f.path)
+	if err != nil {
+		return false, err
+	}
+	defer fh.Close()
+
+	b := make([]byte, len(f.header))
+	if _, err := io.ReadFull(fh, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(b, f.header), nil
+}
+
+// firstRecordTime dates the file at f.path by its first line after
+// the header.
+func (f *File) firstRecordTime() (time.Time, bool) {
+	if f.start == nil {
+		return time.Time{}, false
+	}
+	fh, err := os.Open(f.path)
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer fh.Close()
+
+	r := bufio.NewReader(fh)
+	if _, err := r.Discard(len(f.header)); err != nil {
+		return time.Time{}, false
+	}
+	line, err := r.ReadSlice('\n')
+	if err != nil {
+		return time.Time{}, false
+	}
+	return f.start(bytes.TrimRight(line, "\r\n"))
+}
+
+func (f *File) open() error {
+	fh, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	st, err := fh.Stat()
+	if err != nil {
+		fh.Close()
+		return err
+	}
+	f.f, f.size, f.started = fh, st.Size(), st.ModTime()
+
+	if f.size == 0 {
+		f.started = f.now()
+	} else if t, ok := f.firstRecordTime(); ok {
+		f.started = t
+	}
+	if f.size == 0 && len(f.header) > 0 {
+		n, err := fh.Write(f.header)
+		f.size += int64(n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write appends p, rotating first when p would overflow MaxSize or the
+// file has reached MaxAge. A file holding only its header is never
+// rotated for size, so an oversized record still gets written.
+//
+// A failed background compression is returned by the next Write,
+// after p is written; the rotated file is kept uncompressed.
+func (f *File) Write(p []byte) (int, error) {
+	if f.f == nil {
+		// A rotation failed to reopen the log; try again.
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	var rotErr error
+	if f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			if f.f == nil {
+				return 0, err
+			}
+			rotErr = err
+		}
+	}
+
+	n, err := f.f.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	if rotErr == nil {
+		rotErr = f.compressErr()
+	}
+	return n, rotErr
+}
+
+// due reports whether the file must rotate before writing n bytes.
+func (f *File) due(n int) bool {
+	if f.rot.MaxAge > 0 && f.now().Sub(f.started) >= f.rot.MaxAge {
+		return true
+	}
+	if f.rot.MaxSize > 0 && f.size > int64(len(f.header)) && f.size+int64(n) > f.rot.MaxSize {
+		return true
+	}
+	return false
+}
+
+// rotate moves the current file aside and opens a fresh one. When it
+// cannot, the current file is reopened if possible; an error with f.f
+// set leaves the log writable.
+func (f *File) rotate() error {
+	err := f.f.Close()
+	f.f = nil
+	if err != nil {
+		return err
+	}
+
+	rotated := f.rotatedName()
+	if err := os.Rename(f.path, rotated); err != nil {
+		if oerr := f.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.rot.Gzip {
+		f.gz.Add(1)
+		go func() {
+			defer f.gz.Done()
+			if err := compress(rotated); err != nil {
+				f.mu.Lock()
+				if f.gzErr == nil {
+					f.gzErr = fmt.Errorf("compress %s: %w", rotated, err)
+				}
+				f.mu.Unlock()
+			}
+		}()
+	}
+	return nil
+}
+
+// compressErr returns and clears the first failed compression.
+func (f *File) compressErr() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err := f.gzErr
+	f.gzErr = nil
+	return err
+}
+
+// rotatedName is a free name for the current file, after its start
+// time: dir/poll-20240501T100000Z.csv, with -2, -3 ... on collision.
+func (f *File) rotatedName() string {
+	ext := filepath.Ext(f.path)
+	base := strings.TrimSuffix(f.path, ext) + "-" + f.started.UTC().Format("20060102T150405Z")
+
+	name := base + ext
+	for i := 2; exists(name) || exists(name+".gz"); i++ {
+		name = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+	return name
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// compress replaces path with path.gz.
+func compress(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(path)
+	_, err = io.Copy(zw, in)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	in.Close()
+	return os.Remove(path)
+}
+
+// Close closes the current file and waits for background
+// compressions to finish.
+func (f *File) Close() error {
+	var err error
+	if f.f != nil {
+		err = f.f.Close()
+		f.f = nil
+	}
+	f.gz.Wait()
+	if err == nil {
+		err = f.compressErr()
+	}
+	return err
+}
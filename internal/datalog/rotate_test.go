@@ -0,0 +1,202 @@
+// internal/datalog/rotate_test.go
+package datalog
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source for File.now.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func openTest(t *testing.T, rot Rotation, c *clock) (*File, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "poll.csv")
+	f := &File{path: path, rot: rot, header: []byte("h\n"), now: c.now}
+	if err := f.open(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f, path
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func dirFiles(t *testing.T, path string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFile_RotatesBySize(t *testing.T) {
+	c := &clock{t: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	f, path := openTest(t, Rotation{MaxSize: 10}, c)
+
+	for _, rec := range []string{"aaaa\n", "bbbb\n", "cccc\n"} {
+		if _, err := f.Write([]byte(rec)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// h+aaaa+bbbb is 12 bytes: bbbb starts the next file.
+	names := dirFiles(t, path)
+	want := []string{"poll-20240501T100000Z-2.csv", "poll-20240501T100000Z.csv", "poll.csv"}
+	if len(names) != len(want) {
+		t.Fatalf("files = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("files = %v, want %v", names, want)
+		}
+	}
+	if got := readFile(t, path); got != "h\ncccc\n" {
+		t.Fatalf("current = %q", got)
+	}
+	if got := readFile(t, filepath.Join(filepath.Dir(path), want[1])); got != "h\naaaa\n" {
+		t.Fatalf("first rotated = %q", got)
+	}
+}
+
+func TestFile_OversizedRecordIsWritten(t *testing.T) {
+	f, path := openTest(t, Rotation{MaxSize: 4}, &clock{})
+	if _, err := f.Write([]byte("longer than four\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "h\nlonger than four\n" {
+		t.Fatalf("current = %q", got)
+	}
+	if n := len(dirFiles(t, path)); n != 1 {
+		t.Fatalf("%d files, want 1", n)
+	}
+}
+
+func TestFile_RotatesByAgeWithGzip(t *testing.T) {
+	c := &clock{t: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	f, path := openTest(t, Rotation{MaxAge: time.Hour, Gzip: true}, c)
+
+	f.Write([]byte("a\n"))
+	c.t = c.t.Add(59 * time.Minute)
+	f.Write([]byte("b\n"))
+	c.t = c.t.Add(time.Minute)
+	f.Write([]byte("c\n"))
+	f.gz.Wait() // compression runs in the background
+
+	rotated := filepath.Join(filepath.Dir(path), "poll-20240501T100000Z.csv")
+	if _, err := os.Stat(rotated); !os.IsNotExist(err) {
+		t.Fatalf("uncompressed rotated file kept: %v", err)
+	}
+	zf, err := os.Open(rotated + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zf.Close()
+	zr, err := gzip.NewReader(zf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "h\na\nb\n" {
+		t.Fatalf("rotated = %q", b)
+	}
+	if got := readFile(t, path); got != "h\nc\n" {
+		t.Fatalf("current = %q", got)
+	}
+}
+
+func TestOpenFile_AppendsWithoutSecondHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.csv")
+	for _, rec := range []string{"a\n", "b\n"} {
+		f, err := OpenFile(path, Rotation{}, []byte("h\n"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(rec))
+		f.Close()
+	}
+	if got := readFile(t, path); got != "h\na\nb\n" {
+		t.Fatalf("file = %q", got)
+	}
+}
+
+func TestOpenFile_KeepsAgeOfAppendedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.csv")
+
+	// The file was started two hours ago and last written just now.
+	started := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	last := time.Now().UTC().Truncate(time.Second)
+	old := "h\n" + started.Format(time.RFC3339) + "\n" + last.Format(time.RFC3339) + "\n"
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := func(record []byte) (time.Time, bool) {
+		t, err := time.Parse(time.RFC3339, string(record))
+		return t, err == nil
+	}
+	f, err := OpenFile(path, Rotation{MaxAge: time.Hour}, []byte("h\n"), start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("b\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := filepath.Join(filepath.Dir(path), "poll-"+started.Format("20060102T150405Z")+".csv")
+	if got := readFile(t, rotated); got != old {
+		t.Fatalf("rotated = %q", got)
+	}
+	if got := readFile(t, path); got != "h\nb\n" {
+		t.Fatalf("current = %q", got)
+	}
+}
+
+func TestOpenFile_RotatesOnHeaderChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "poll.csv")
+	if err := os.WriteFile(path, []byte("old\na\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := OpenFile(path, Rotation{}, []byte("h\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("b\n"))
+	f.Close()
+
+	names := dirFiles(t, path)
+	if len(names) != 2 {
+		t.Fatalf("files = %v, want the old log and poll.csv", names)
+	}
+	if got := readFile(t, filepath.Join(filepath.Dir(path), names[0])); got != "old\na\n" {
+		t.Fatalf("old log = %q", got)
+	}
+	if got := readFile(t, path); got != "h\nb\n" {
+		t.Fatalf("current = %q", got)
+	}
+}
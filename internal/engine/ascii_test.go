@@ -0,0 +1,72 @@
+// internal/engine/ascii_test.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+var asciiOverTCP = client.Transport{Framing: client.FramingASCII}
+
+func TestPersistentEngine_ASCIIOverTCP(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) { serveASCII(c, 4, nil) })
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: asciiOverTCP}
+	defer eng.Close()
+
+	for i := 0; i < 3; i++ {
+		req := Request{UnitID: 4, FunctionCode: 3, Address: uint16(20 * i), Quantity: 5, Timeout: time.Second}
+
+		res := eng.Execute(context.Background(), req)
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		values, err := format.DecodeReadValues(res.Raw, 3, 5)
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if values[0] != uint16(20*i) || values[4] != uint16(20*i+4) {
+			t.Fatalf("unexpected values %v", values)
+		}
+	}
+
+	res := eng.Execute(context.Background(), Request{UnitID: 4, FunctionCode: 3, Address: 120, Quantity: 1, Timeout: time.Second})
+	if me, ok := client.IsModbusException(res.Err); !ok || me.Code != 2 {
+		t.Fatalf("expected exception 2, got %v", res.Err)
+	}
+
+	if s := eng.ConnStats(); s.Dials != 1 {
+		t.Fatalf("exceptions must keep the connection, got %+v", s)
+	}
+}
+
+func TestModbusEngine_ASCIIBadLRC(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		serveASCII(c, 1, func(adu []byte) []byte {
+			// Corrupt the first data digit; the LRC no longer matches.
+			if adu[5] == '0' {
+				adu[5] = '1'
+			} else {
+				adu[5] = '0'
+			}
+			return adu
+		})
+	})
+
+	eng := &ModbusEngine{TargetAddr: addr, Transport: asciiOverTCP}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Quantity: 2, Timeout: time.Second})
+
+	var ce *client.ChecksumError
+	if !errors.As(res.Err, &ce) || ce.Kind != "lrc" {
+		t.Fatalf("expected lrc error, got %v", res.Err)
+	}
+	if _, ok := client.IsModbusException(res.Err); ok {
+		t.Fatalf("lrc error must not be a modbus exception")
+	}
+}
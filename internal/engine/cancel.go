@@ -0,0 +1,85 @@
+// internal/engine/cancel.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// CanceledError reports a request abandoned because its context ended
+// (Ctrl+C, end of a stress run, caller deadline). It is not a device
+// error and is never a Modbus exception.
+type CanceledError struct {
+	Err error // context.Canceled or context.DeadlineExceeded
+}
+
+func (e *CanceledError) Error() string {
+	return "request canceled: " + e.Err.Error()
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// IsCanceled reports whether err is a CanceledError.
+func IsCanceled(err error) bool {
+	var ce *CanceledError
+	return errors.As(err, &ce)
+}
+
+// ctxErr replaces err with a CanceledError when ctx has ended,
+// because any I/O failure after cancellation is caused by it.
+// A socket timeout that fired at the ctx deadline counts as well,
+// since the deadline was derived from ctx.
+func ctxErr(ctx context.Context, err error) error {
+	if err == nil || IsCanceled(err) {
+		return err
+	}
+	if ctx.Err() != nil {
+		return &CanceledError{Err: ctx.Err()}
+	}
+	if dl, ok := ctx.Deadline(); ok && isTimeout(err) && !time.Now().Before(dl) {
+		return &CanceledError{Err: context.DeadlineExceeded}
+	}
+	return err
+}
+
+// effectiveTimeout bounds the per-request timeout by the ctx deadline.
+func effectiveTimeout(ctx context.Context, timeout time.Duration) time.Duration {
+	if dl, ok := ctx.Deadline(); ok {
+		if until := time.Until(dl); until < timeout {
+			return until
+		}
+	}
+	return timeout
+}
+
+// watchContext closes conn if ctx ends before stop is called,
+// unblocking any socket read or write in progress.
+// stop reports whether the connection was closed by the watcher.
+func watchContext(ctx context.Context, conn *client.Connection) (stop func() bool) {
+	if ctx.Done() == nil {
+		return func() bool { return false }
+	}
+
+	done := make(chan struct{})
+	fired := make(chan bool, 1)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+			fired <- true
+		case <-done:
+			fired <- false
+		}
+	}()
+
+	return func() bool {
+		close(done)
+		return <-fired
+	}
+}
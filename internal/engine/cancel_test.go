@@ -0,0 +1,104 @@
+// internal/engine/cancel_test.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// silentServer reads requests and never answers.
+func silentServer(c net.Conn) {
+	_, _ = io.Copy(io.Discard, c)
+}
+
+func TestModbusEngine_CancelAbortsBlockedRead(t *testing.T) {
+	addr := startModbusTCPResponder(t, silentServer)
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 5 * time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	res := eng.Execute(ctx, req)
+
+	if time.Since(start) > time.Second {
+		t.Fatalf("Execute ignored cancellation (took %v)", time.Since(start))
+	}
+	if !IsCanceled(res.Err) || !errors.Is(res.Err, context.Canceled) {
+		t.Fatalf("expected CanceledError, got %v", res.Err)
+	}
+	if _, ok := client.IsModbusException(res.Err); ok {
+		t.Fatalf("cancellation must not look like a Modbus exception")
+	}
+}
+
+func TestModbusEngine_ContextDeadlineBoundsTimeout(t *testing.T) {
+	addr := startModbusTCPResponder(t, silentServer)
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 5 * time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	res := eng.Execute(ctx, req)
+
+	if time.Since(start) > time.Second {
+		t.Fatalf("Execute ignored ctx deadline (took %v)", time.Since(start))
+	}
+	if !IsCanceled(res.Err) || !errors.Is(res.Err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline CanceledError, got %v", res.Err)
+	}
+}
+
+func TestPersistentEngine_CancelDropsConnection(t *testing.T) {
+	addr := startModbusTCPResponder(t, silentServer)
+
+	eng := &PersistentEngine{TargetAddr: addr}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 5 * time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if res := eng.Execute(ctx, req); !IsCanceled(res.Err) {
+		t.Fatalf("expected CanceledError, got %v", res.Err)
+	}
+	if s := eng.ConnStats(); s.Discarded != 1 {
+		t.Fatalf("expected the interrupted connection to be discarded, got %+v", s)
+	}
+
+	// Already-cancelled context: no I/O at all.
+	if res := eng.Execute(ctx, req); !IsCanceled(res.Err) {
+		t.Fatalf("expected CanceledError, got %v", res.Err)
+	}
+	if s := eng.ConnStats(); s.Dials != 1 {
+		t.Fatalf("expected no dial after cancellation, got %+v", s)
+	}
+}
+
+func TestPipelineEngine_CancelWhileWaiting(t *testing.T) {
+	addr := startModbusTCPResponder(t, silentServer)
+
+	eng := &PipelineEngine{TargetAddr: addr, Depth: 2}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 5 * time.Second}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if res := eng.Execute(ctx, req); !IsCanceled(res.Err) {
+		t.Fatalf("expected CanceledError, got %v", res.Err)
+	}
+}
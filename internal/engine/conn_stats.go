@@ -0,0 +1,124 @@
+// internal/engine/conn_stats.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// ConnStats describes connection reuse for engines that keep sockets open.
+type ConnStats struct {
+	Dials     uint64 // successful dials
+	Reuses    uint64 // requests served on an already-open connection
+	Discarded uint64 // connections closed after a transport error
+
+	// UDP only.
+	Retransmits uint64 // requests resent after a timeout
+	Late        uint64 // responses that arrived after their request gave up
+	Duplicates  uint64 // responses to an already answered request
+	Malformed   uint64 // datagrams that are not MBAP frames
+}
+
+// connTracker counts connection lifecycle events. Safe for concurrent use.
+type connTracker struct {
+	dials     uint64
+	reuses    uint64
+	discarded uint64
+
+	retransmits uint64
+	lates       uint64
+	duplicates  uint64
+	malformeds  uint64
+}
+
+func (t *connTracker) dialed()  { atomic.AddUint64(&t.dials, 1) }
+func (t *connTracker) reused()  { atomic.AddUint64(&t.reuses, 1) }
+func (t *connTracker) dropped() { atomic.AddUint64(&t.discarded, 1) }
+
+// Datagram counters are kept only by engines that track stats; t may be nil.
+func (t *connTracker) retransmitted() { t.add(&t.retransmits) }
+func (t *connTracker) late()          { t.add(&t.lates) }
+func (t *connTracker) duplicate()     { t.add(&t.duplicates) }
+func (t *connTracker) malformed()     { t.add(&t.malformeds) }
+
+func (t *connTracker) add(n *uint64) {
+	if t != nil {
+		atomic.AddUint64(n, 1)
+	}
+}
+
+func (t *connTracker) snapshot() ConnStats {
+	return ConnStats{
+		Dials:     atomic.LoadUint64(&t.dials),
+		Reuses:    atomic.LoadUint64(&t.reuses),
+		Discarded: atomic.LoadUint64(&t.discarded),
+
+		Retransmits: atomic.LoadUint64(&t.retransmits),
+		Late:        atomic.LoadUint64(&t.lates),
+		Duplicates:  atomic.LoadUint64(&t.duplicates),
+		Malformed:   atomic.LoadUint64(&t.malformeds),
+	}
+}
+
+// exchange runs req on a kept session. If s was reused and the peer
+// closed it while idle, redial opens a fresh session and a repeatable
+// request is retried once; timeouts and cancellations are not retried.
+// Writes are never retried: the device may have applied the write
+// before the connection broke.
+//
+// The returned session is nil when the connection must not be kept:
+// transport and framing errors leave the stream in an unknown state.
+// Exceptions, and timeouts on UDP, keep it.
+func exchange(
+	ctx context.Context,
+	s *session,
+	reused bool,
+	req Request,
+	strict bool,
+	t *connTracker,
+	redial func() (*session, error),
+) (*session, []byte, error) {
+
+	raw, err := s.roundTrip(ctx, req, strict)
+
+	if reused && client.IsBrokenConn(err) && !isTimeout(err) && !IsCanceled(err) && repeatable(req) {
+		s.close()
+		t.dropped()
+
+		if s, err = redial(); err != nil {
+			return nil, nil, err
+		}
+		raw, err = s.roundTrip(ctx, req, strict)
+	}
+
+	if err != nil && !keepAfter(s, err) {
+		s.close()
+		t.dropped()
+		return nil, nil, err
+	}
+
+	return s, raw, err
+}
+
+// repeatable reports whether sending req twice has the effect of
+// sending it once: reads, and the diagnostics that only return data
+// or counters (FC 8 sub-functions 0x00, 0x02 and 0x0B-0x12).
+func repeatable(req Request) bool {
+	switch req.FunctionCode {
+	case 1, 2, 3, 4, 7, 11, 12, 17, 20, 24, 43:
+		return true
+	case 8:
+		sub := req.SubFunction
+		return sub == 0x00 || sub == 0x02 || sub >= 0x0B && sub <= 0x12
+	}
+	return false
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
@@ -0,0 +1,47 @@
+// internal/engine/deviceid_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestModbusEngine_ReadDeviceID(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func(req []byte) []byte {
+		resp := []byte{43, 14, 1, 0x81, 0x00, 0x00, 3}
+		for i, v := range []string{"Acme", "PM-100", "v1.2"} {
+			resp = append(resp, byte(i), byte(len(v)))
+			resp = append(resp, v...)
+		}
+		return resp
+	}))
+
+	// Lenient parsing must size the body from the object list alone.
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 43, DeviceIDCode: 1, Timeout: time.Second}
+
+	res := eng.Execute(context.Background(), req)
+	if res.Err != nil {
+		t.Fatalf("read device id failed: %v", res.Err)
+	}
+
+	if pdu := <-got; !bytes.Equal(pdu, []byte{43, 14, 1, 0}) {
+		t.Fatalf("unexpected PDU % x", pdu)
+	}
+
+	id, err := format.DecodeDeviceID(res.Raw)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if id.MoreFollows || len(id.Objects) != 3 || id.Objects[1].Value != "PM-100" {
+		t.Fatalf("unexpected device id %+v", id)
+	}
+	if format.DeviceObjectName(id.Objects[2].ID) != "MajorMinorRevision" {
+		t.Fatalf("unexpected object name %q", format.DeviceObjectName(id.Objects[2].ID))
+	}
+}
@@ -0,0 +1,75 @@
+// internal/engine/diag_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestModbusEngine_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     Request
+		wantReq []byte
+		resp    []byte
+	}{
+		{"exception status", Request{FunctionCode: 7}, []byte{7}, []byte{7, 0x6D}},
+		{"bus message count", Request{FunctionCode: 8, SubFunction: 0x0B}, []byte{8, 0x00, 0x0B, 0x00, 0x00}, []byte{8, 0x00, 0x0B, 0x01, 0x2C}},
+		{"comm event counter", Request{FunctionCode: 11}, []byte{11}, []byte{11, 0xFF, 0xFF, 0x01, 0x08}},
+		{"comm event log", Request{FunctionCode: 12}, []byte{12}, []byte{12, 8, 0x00, 0x00, 0x01, 0x08, 0x01, 0x21, 0x20, 0x00}},
+		{"server id", Request{FunctionCode: 17}, []byte{17}, []byte{17, 4, 0x2A, 0xFF, 'R', 'X'}},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			got := make(chan []byte, 1)
+			resp := tt.resp
+			addr := startModbusTCPResponder(t, pduServer(got, func([]byte) []byte { return resp }))
+
+			eng := &ModbusEngine{TargetAddr: addr, Strict: strict}
+			req := tt.req
+			req.UnitID = 1
+			req.Timeout = time.Second
+
+			res := eng.Execute(context.Background(), req)
+			if res.Err != nil {
+				t.Fatalf("%s (strict=%v): %v", tt.name, strict, res.Err)
+			}
+			if pdu := <-got; !bytes.Equal(pdu, tt.wantReq) {
+				t.Fatalf("%s: unexpected request PDU % x", tt.name, pdu)
+			}
+			if !bytes.Equal(res.Raw, tt.resp) {
+				t.Fatalf("%s: unexpected response % x", tt.name, res.Raw)
+			}
+		}
+	}
+
+	if _, count, _ := format.DecodeDiagnostics([]byte{8, 0x00, 0x0B, 0x01, 0x2C}); count != 300 {
+		t.Fatalf("unexpected bus message count %d", count)
+	}
+	log, err := format.DecodeCommEventLog([]byte{12, 8, 0x00, 0x00, 0x01, 0x08, 0x01, 0x21, 0x20, 0x00})
+	if err != nil || log.EventCount != 0x108 || log.MessageCount != 0x121 || len(log.Events) != 2 {
+		t.Fatalf("unexpected event log %+v (%v)", log, err)
+	}
+	id, err := format.DecodeServerID([]byte{17, 4, 0x2A, 0xFF, 'R', 'X'})
+	if err != nil || id.ID != 0x2A || !id.Running || string(id.Additional) != "RX" {
+		t.Fatalf("unexpected server id %+v (%v)", id, err)
+	}
+}
+
+func TestModbusEngine_DiagnosticsEchoMismatch(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func([]byte) []byte {
+		return []byte{8, 0x00, 0x0C, 0x00, 0x00} // answers a different sub-function
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 8, SubFunction: 0x0B, Timeout: time.Second})
+	if res.Err == nil {
+		t.Fatalf("expected sub-function echo mismatch")
+	}
+}
@@ -4,6 +4,8 @@ package engine
 import (
 	"context"
 	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
 )
 
 type Request struct {
@@ -12,6 +14,37 @@ type Request struct {
 	Address      uint16
 	Quantity     uint16
 	Timeout      time.Duration
+
+	// Value is the payload of single writes: FC 5 (non-zero = ON)
+	// and FC 6, and the data word of FC 8. Ignored by reads.
+	Value uint16
+
+	// SubFunction selects the FC 8 diagnostic.
+	SubFunction uint16
+
+	// Values is the payload of multiple writes: FC 15 (one coil per
+	// element, non-zero = ON), FC 16 and the write part of FC 23.
+	// The written quantity is len(Values); for FC 15/16 Quantity is
+	// informational, for FC 23 it is the read quantity.
+	Values []uint16
+
+	// WriteAddress is the write start address of FC 23.
+	// Address and Quantity describe its read part.
+	WriteAddress uint16
+
+	// AndMask and OrMask are the FC 22 masks applied to Address.
+	AndMask uint16
+	OrMask  uint16
+
+	// Records are the FC 20/21 file record sub-requests. FC 24 reads
+	// the FIFO whose pointer register is Address.
+	Records []client.FileRecord
+
+	// DeviceIDCode and ObjectID select the FC 43 / MEI 14 access:
+	// basic (1), regular (2), extended (3) or individual (4), starting
+	// at ObjectID.
+	DeviceIDCode uint8
+	ObjectID     uint8
 }
 
 type Result struct {
@@ -20,7 +53,7 @@ type Result struct {
 	Address      uint16
 	Quantity     uint16
 
-	// Raw is protocol-level bytes only (PDU-ish buffer as returned by parser).
+	// Raw is the response PDU [FC][Data...] as returned by the parser.
 	// No decoding, scaling, or interpretation happens here.
 	Raw []byte
 
@@ -0,0 +1,117 @@
+// internal/engine/filerecord_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestModbusEngine_ReadFileRecord(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func([]byte) []byte {
+		return []byte{
+			20, 12,
+			5, 6, 0x0D, 0xFE, 0x00, 0x20, // file 4 record 1, 2 registers
+			5, 6, 0x33, 0xCD, 0x00, 0x40, // file 3 record 9, 2 registers
+		}
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 20,
+		Records:      []client.FileRecord{{File: 4, Record: 1, Length: 2}, {File: 3, Record: 9, Length: 2}},
+		Timeout:      time.Second,
+	}
+
+	res := eng.Execute(context.Background(), req)
+	if res.Err != nil {
+		t.Fatalf("read file record failed: %v", res.Err)
+	}
+
+	want := []byte{20, 14, 6, 0x00, 0x04, 0x00, 0x01, 0x00, 0x02, 6, 0x00, 0x03, 0x00, 0x09, 0x00, 0x02}
+	if pdu := <-got; !bytes.Equal(pdu, want) {
+		t.Fatalf("unexpected PDU % x, want % x", pdu, want)
+	}
+
+	groups, err := format.DecodeFileRecords(res.Raw)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(groups) != 2 || groups[0][0] != 0x0DFE || groups[1][1] != 0x0040 {
+		t.Fatalf("unexpected records %#v", groups)
+	}
+}
+
+func TestModbusEngine_WriteFileRecord(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func(req []byte) []byte {
+		return req // FC 21 echoes the request
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 21,
+		Records:      []client.FileRecord{{File: 4, Record: 7, Data: []uint16{0x06AF, 0x04BE, 0x100D}}},
+		Timeout:      time.Second,
+	}
+
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("write file record failed: %v", res.Err)
+	}
+
+	want := []byte{21, 13, 6, 0x00, 0x04, 0x00, 0x07, 0x00, 0x03, 0x06, 0xAF, 0x04, 0xBE, 0x10, 0x0D}
+	if pdu := <-got; !bytes.Equal(pdu, want) {
+		t.Fatalf("unexpected PDU % x, want % x", pdu, want)
+	}
+}
+
+func TestModbusEngine_FileRecordLimits(t *testing.T) {
+	// 2 x (2 + 2*100) response bytes exceed one PDU.
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 20,
+		Records:      []client.FileRecord{{File: 1, Length: 100}, {File: 1, Record: 100, Length: 100}},
+	}
+	if _, err := buildFrame(client.NewRequest(), req); err == nil {
+		t.Fatalf("expected oversize file record request to be rejected")
+	}
+
+	req.Records = []client.FileRecord{{File: 1, Record: 10000, Length: 1}}
+	if _, err := buildFrame(client.NewRequest(), req); err == nil {
+		t.Fatalf("expected record number above 9999 to be rejected")
+	}
+}
+
+func TestModbusEngine_ReadFIFO(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func([]byte) []byte {
+		return []byte{24, 0x00, 0x06, 0x00, 0x02, 0x01, 0xB8, 0x12, 0x84}
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 24, Address: 0x04DE, Timeout: time.Second}
+
+	res := eng.Execute(context.Background(), req)
+	if res.Err != nil {
+		t.Fatalf("read fifo failed: %v", res.Err)
+	}
+
+	if pdu := <-got; !bytes.Equal(pdu, []byte{24, 0x04, 0xDE}) {
+		t.Fatalf("unexpected PDU % x", pdu)
+	}
+
+	values, err := format.DecodeFIFO(res.Raw)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if len(values) != 2 || values[0] != 0x01B8 || values[1] != 0x1284 {
+		t.Fatalf("unexpected fifo %#v", values)
+	}
+}
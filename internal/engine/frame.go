@@ -0,0 +1,102 @@
+// internal/engine/frame.go
+package engine
+
+import (
+	"fmt"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// maxADU is the largest Modbus TCP frame (MBAP + 253-byte PDU).
+const maxADU = 260
+
+// buildFrame builds the MBAP request frame for req using tx for the
+// transaction id. The request PDU starts at frame[7].
+func buildFrame(tx *client.Request, req Request) ([]byte, error) {
+	buf := make([]byte, maxADU)
+
+	switch req.FunctionCode {
+	case 1, 2, 3, 4:
+		return tx.BuildReadRequest(buf, req.UnitID, req.FunctionCode, req.Address, req.Quantity), nil
+	case 5, 6:
+		return tx.BuildWriteSingleRequest(buf, req.UnitID, req.FunctionCode, req.Address, req.Value), nil
+	case 7, 11, 12, 17:
+		return tx.BuildNoDataRequest(buf, req.UnitID, req.FunctionCode), nil
+	case 8:
+		return tx.BuildDiagnosticsRequest(buf, req.UnitID, req.SubFunction, req.Value), nil
+	case 15:
+		if len(req.Values) == 0 || len(req.Values) > client.MaxWriteCoils {
+			return nil, fmt.Errorf("fc 15 needs 1..%d values, got %d", client.MaxWriteCoils, len(req.Values))
+		}
+		return tx.BuildWriteMultipleCoilsRequest(buf, req.UnitID, req.Address, req.Values), nil
+	case 16:
+		if len(req.Values) == 0 || len(req.Values) > client.MaxWriteRegisters {
+			return nil, fmt.Errorf("fc 16 needs 1..%d values, got %d", client.MaxWriteRegisters, len(req.Values))
+		}
+		return tx.BuildWriteMultipleRegistersRequest(buf, req.UnitID, req.Address, req.Values), nil
+	case 20:
+		if err := checkFileRecords(req.Records, false); err != nil {
+			return nil, err
+		}
+		return tx.BuildReadFileRecordRequest(buf, req.UnitID, req.Records), nil
+	case 21:
+		if err := checkFileRecords(req.Records, true); err != nil {
+			return nil, err
+		}
+		return tx.BuildWriteFileRecordRequest(buf, req.UnitID, req.Records), nil
+	case 24:
+		return tx.BuildReadFIFORequest(buf, req.UnitID, req.Address), nil
+	case 22:
+		return tx.BuildMaskWriteRequest(buf, req.UnitID, req.Address, req.AndMask, req.OrMask), nil
+	case 23:
+		if req.Quantity == 0 || req.Quantity > client.MaxReadWriteRead {
+			return nil, fmt.Errorf("fc 23 reads 1..%d registers, got %d", client.MaxReadWriteRead, req.Quantity)
+		}
+		if len(req.Values) == 0 || len(req.Values) > client.MaxReadWriteWrite {
+			return nil, fmt.Errorf("fc 23 needs 1..%d values, got %d", client.MaxReadWriteWrite, len(req.Values))
+		}
+		return tx.BuildReadWriteMultipleRequest(buf, req.UnitID, req.Address, req.Quantity, req.WriteAddress, req.Values), nil
+	case 43:
+		if req.DeviceIDCode < 1 || req.DeviceIDCode > 4 {
+			return nil, fmt.Errorf("fc 43 device id code must be 1..4, got %d", req.DeviceIDCode)
+		}
+		return tx.BuildReadDeviceIDRequest(buf, req.UnitID, req.DeviceIDCode, req.ObjectID), nil
+	default:
+		return nil, fmt.Errorf("unsupported function code: %d", req.FunctionCode)
+	}
+}
+
+// checkFileRecords validates FC 20 (read) or FC 21 (write) sub-requests.
+// Both the request and the response must fit one PDU.
+func checkFileRecords(records []client.FileRecord, write bool) error {
+	if len(records) == 0 {
+		return fmt.Errorf("file record access needs at least one record")
+	}
+
+	reqBytes, respBytes := 0, 0
+	for _, rec := range records {
+		n := int(rec.Length)
+		if write {
+			n = len(rec.Data)
+		}
+		if n == 0 {
+			return fmt.Errorf("file %d record %d: length must be > 0", rec.File, rec.Record)
+		}
+		if rec.Record > client.MaxFileRecordNum {
+			return fmt.Errorf("file %d record %d: record must be 0..%d", rec.File, rec.Record, client.MaxFileRecordNum)
+		}
+
+		if write {
+			reqBytes += 7 + 2*n
+			respBytes = reqBytes // echo
+		} else {
+			reqBytes += 7
+			respBytes += 2 + 2*n // [FileRespLen][RefType][Data...]
+		}
+	}
+
+	if reqBytes > client.MaxFileData || respBytes > client.MaxFileData {
+		return fmt.Errorf("file records exceed one PDU: %d request / %d response bytes, max %d", reqBytes, respBytes, client.MaxFileData)
+	}
+	return nil
+}
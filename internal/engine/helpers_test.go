@@ -0,0 +1,168 @@
+// internal/engine/helpers_test.go
+package engine
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// startModbusTCPResponder accepts any number of connections and runs
+// handler for each one in its own goroutine until the test ends.
+func startModbusTCPResponder(t *testing.T, handler func(net.Conn)) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		conns []net.Conn
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, c)
+			mu.Unlock()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.Close()
+				handler(c)
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+		mu.Lock()
+		for _, c := range conns {
+			_ = c.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+	})
+
+	return ln.Addr().String()
+}
+
+// serveFC3 answers one FC3 request with registers equal to their address.
+func serveFC3(c net.Conn) error {
+	req := make([]byte, 12)
+	if _, err := io.ReadFull(c, req); err != nil {
+		return err
+	}
+	_, err := c.Write(fc3Response(req))
+	return err
+}
+
+// fc3Response answers a 12-byte FC3 request with registers equal to their address.
+func fc3Response(req []byte) []byte {
+	addr := binary.BigEndian.Uint16(req[8:10])
+	qty := int(binary.BigEndian.Uint16(req[10:12]))
+
+	resp := make([]byte, 9+2*qty)
+	copy(resp[0:2], req[0:2]) // TxID
+	binary.BigEndian.PutUint16(resp[4:6], uint16(3+2*qty))
+	resp[6] = req[6]
+	resp[7] = req[7]
+	resp[8] = byte(2 * qty)
+	for i := 0; i < qty; i++ {
+		binary.BigEndian.PutUint16(resp[9+2*i:], addr+uint16(i))
+	}
+	return resp
+}
+
+// serveRTU answers RTU FC3 requests on m (a pty master or a socket)
+// until it closes.
+// Registers equal their address; mutate may alter each response ADU.
+func serveRTU(m io.ReadWriter, unitID uint8, mutate func([]byte) []byte) {
+	for {
+		req := make([]byte, 8) // [unit][3][addr(2)][qty(2)][crc(2)]
+		if _, err := io.ReadFull(m, req); err != nil {
+			return
+		}
+		if req[0] != unitID {
+			continue // another device's frame: stay silent
+		}
+		if binary.LittleEndian.Uint16(req[6:]) != client.CRC16(req[:6]) {
+			continue // corrupt request: stay silent
+		}
+
+		addr := binary.BigEndian.Uint16(req[2:4])
+		qty := binary.BigEndian.Uint16(req[4:6])
+
+		pdu := []byte{3, byte(2 * qty)}
+		if addr >= 100 {
+			pdu = []byte{0x83, 2} // illegal data address
+		} else {
+			for i := uint16(0); i < qty; i++ {
+				pdu = append(pdu, byte((addr+i)>>8), byte(addr+i))
+			}
+		}
+
+		adu := client.EncodeRTU(unitID, pdu)
+		if mutate != nil {
+			adu = mutate(adu)
+		}
+		if _, err := m.Write(adu); err != nil {
+			return
+		}
+	}
+}
+
+// serveASCII answers ASCII FC3 requests on m until it closes.
+// Registers equal their address; addresses from 100 raise exception 2.
+// mutate may alter each response line.
+func serveASCII(m io.ReadWriter, unitID uint8, mutate func([]byte) []byte) {
+	r := bufio.NewReader(m)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		// ":" + hex([unit][3][addr(2)][qty(2)][lrc]) + CRLF
+		req, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(string(line), ":")))
+		if err != nil || len(req) != 7 || req[0] != unitID || client.LRC(req[:6]) != req[6] {
+			continue // another device's or a corrupt frame: stay silent
+		}
+
+		addr := binary.BigEndian.Uint16(req[2:4])
+		qty := binary.BigEndian.Uint16(req[4:6])
+
+		pdu := []byte{3, byte(2 * qty)}
+		if addr >= 100 {
+			pdu = []byte{0x83, 2} // illegal data address
+		} else {
+			for i := uint16(0); i < qty; i++ {
+				pdu = append(pdu, byte((addr+i)>>8), byte(addr+i))
+			}
+		}
+
+		adu := client.EncodeASCII(unitID, pdu)
+		if mutate != nil {
+			adu = mutate(adu)
+		}
+		if _, err := m.Write(adu); err != nil {
+			return
+		}
+	}
+}
@@ -8,88 +8,41 @@ import (
 	"github.com/tamzrod/rdxbus/internal/client"
 )
 
-// ModbusEngine is the single execution throat for Modbus TCP.
+// ModbusEngine is the single execution throat for Modbus.
 // It executes exactly one request per call.
 type ModbusEngine struct {
 	TargetAddr string
 	Strict     bool
+
+	// Transport selects the link and framing; the zero value is
+	// Modbus TCP to TargetAddr.
+	Transport client.Transport
+
+	handshakes tlsTracker
 }
 
 func (e *ModbusEngine) Execute(ctx context.Context, req Request) Result {
 	start := time.Now()
 
-	// Dial per call for now (simple + correct).
-	conn, err := client.Dial(e.TargetAddr, req.Timeout)
-	if err != nil {
-		return Result{
-			UnitID:       req.UnitID,
-			FunctionCode: req.FunctionCode,
-			Address:      req.Address,
-			Quantity:     req.Quantity,
-			Duration:     time.Since(start),
-			Err:          err,
-		}
-	}
-	defer conn.Close()
-
-	// Build request frame
-	r := client.NewRequest()
-	reqBuf := make([]byte, 12) // request.go expects >=12
-	frame := r.BuildReadRequest(reqBuf, req.UnitID, req.FunctionCode, req.Address, req.Quantity)
-	expectedTxID := r.TxID()
-
-	if err := conn.Write(frame); err != nil {
-		return Result{
-			UnitID:       req.UnitID,
-			FunctionCode: req.FunctionCode,
-			Address:      req.Address,
-			Quantity:     req.Quantity,
-			Duration:     time.Since(start),
-			Err:          err,
-		}
+	if err := ctx.Err(); err != nil {
+		return newResult(req, start, nil, &CanceledError{Err: err})
 	}
 
-	// Read MBAP header (7 bytes)
-	hdr := make([]byte, 7)
-	if err := conn.ReadFull(hdr); err != nil {
-		return Result{
-			UnitID:       req.UnitID,
-			FunctionCode: req.FunctionCode,
-			Address:      req.Address,
-			Quantity:     req.Quantity,
-			Duration:     time.Since(start),
-			Err:          err,
-		}
+	// Dial (or open the serial port) per call.
+	conn, err := client.Open(ctx, e.TargetAddr, e.Transport, effectiveTimeout(ctx, req.Timeout))
+	if err != nil {
+		return newResult(req, start, nil, ctxErr(ctx, err))
 	}
+	e.handshakes.observe(conn)
 
-	// Parse response into PDU buffer
-	parser := client.NewResponseParser(e.Strict)
-
-	// Oversize buffer is fine; higher layers can decode from the prefix.
-	pduBuf := make([]byte, 512)
-
-	if err := parser.Parse(conn, expectedTxID, req.FunctionCode, hdr, pduBuf); err != nil {
-		return Result{
-			UnitID:       req.UnitID,
-			FunctionCode: req.FunctionCode,
-			Address:      req.Address,
-			Quantity:     req.Quantity,
-			Duration:     time.Since(start),
-			Err:          err,
-		}
-	}
+	s := newSession(conn)
+	defer s.close()
 
-	// Return protocol-level bytes only (no decoding here).
-	raw := make([]byte, len(pduBuf))
-	copy(raw, pduBuf)
+	raw, err := s.roundTrip(ctx, req, e.Strict)
+	return newResult(req, start, raw, err)
+}
 
-	return Result{
-		UnitID:       req.UnitID,
-		FunctionCode: req.FunctionCode,
-		Address:      req.Address,
-		Quantity:     req.Quantity,
-		Raw:          raw,
-		Duration:     time.Since(start),
-		Err:          nil,
-	}
+// TLSInfo returns the most recent TLS handshake, or nil without TLS.
+func (e *ModbusEngine) TLSInfo() *client.TLSInfo {
+	return e.handshakes.last()
 }
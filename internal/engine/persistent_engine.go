@@ -0,0 +1,122 @@
+// internal/engine/persistent_engine.go
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// PersistentEngine keeps one long-lived connection to TargetAddr and
+// reuses it across requests. Requests are serialized on that socket.
+//
+// A connection that fails with a transport error is discarded and
+// redialed on the next request. Failed dials back off exponentially
+// from Backoff up to MaxBackoff.
+type PersistentEngine struct {
+	TargetAddr string
+	Strict     bool
+	Transport  client.Transport
+
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+
+	mu       sync.Mutex
+	sess     *session
+	delay    time.Duration
+	nextDial time.Time
+
+	stats      connTracker
+	handshakes tlsTracker
+}
+
+func (e *PersistentEngine) Execute(ctx context.Context, req Request) Result {
+	start := time.Now()
+
+	if err := ctx.Err(); err != nil {
+		return newResult(req, start, nil, &CanceledError{Err: err})
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	reused := e.sess != nil
+	if reused {
+		e.stats.reused()
+	} else {
+		s, err := e.dial(ctx, req.Timeout)
+		if err != nil {
+			return newResult(req, start, nil, err)
+		}
+		e.sess = s
+	}
+
+	redial := func() (*session, error) { return e.dial(ctx, req.Timeout) }
+
+	var raw []byte
+	var err error
+	e.sess, raw, err = exchange(ctx, e.sess, reused, req, e.Strict, &e.stats, redial)
+
+	return newResult(req, start, raw, err)
+}
+
+// dial opens a new connection, waiting out any pending backoff first.
+func (e *PersistentEngine) dial(ctx context.Context, timeout time.Duration) (*session, error) {
+	if wait := time.Until(e.nextDial); wait > 0 {
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, &CanceledError{Err: ctx.Err()}
+		case <-t.C:
+		}
+	}
+
+	conn, err := client.Open(ctx, e.TargetAddr, e.Transport, effectiveTimeout(ctx, timeout))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctxErr(ctx, err)
+		}
+		e.delay *= 2
+		if e.delay < e.Backoff {
+			e.delay = e.Backoff
+		}
+		if e.MaxBackoff > 0 && e.delay > e.MaxBackoff {
+			e.delay = e.MaxBackoff
+		}
+		e.nextDial = time.Now().Add(e.delay)
+		return nil, err
+	}
+
+	e.delay = 0
+	e.nextDial = time.Time{}
+	e.stats.dialed()
+	e.handshakes.observe(conn)
+	s := newSession(conn)
+	s.stats = &e.stats
+	return s, nil
+}
+
+// ConnStats returns a snapshot of connection reuse counters.
+func (e *PersistentEngine) ConnStats() ConnStats {
+	return e.stats.snapshot()
+}
+
+// TLSInfo returns the most recent TLS handshake, or nil without TLS.
+func (e *PersistentEngine) TLSInfo() *client.TLSInfo {
+	return e.handshakes.last()
+}
+
+// Close releases the current connection, if any.
+func (e *PersistentEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.sess != nil {
+		e.sess.close()
+		e.sess = nil
+	}
+	return nil
+}
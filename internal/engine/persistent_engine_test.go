@@ -0,0 +1,172 @@
+// internal/engine/persistent_engine_test.go
+package engine
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestPersistentEngine_ReusesConnection(t *testing.T) {
+	var accepted int32
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		atomic.AddInt32(&accepted, 1)
+		for serveFC3(c) == nil {
+		}
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr}
+	defer eng.Close()
+
+	for i := 0; i < 5; i++ {
+		req := Request{UnitID: 1, FunctionCode: 3, Address: uint16(i), Quantity: 1, Timeout: time.Second}
+		res := eng.Execute(context.Background(), req)
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		values, err := format.DecodeReadValues(res.Raw, 3, 1)
+		if err != nil || values[0] != uint16(i) {
+			t.Fatalf("request %d: unexpected values %v (%v)", i, values, err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&accepted); n != 1 {
+		t.Fatalf("expected 1 connection, got %d", n)
+	}
+	if s := eng.ConnStats(); s.Dials != 1 || s.Reuses != 4 {
+		t.Fatalf("unexpected conn stats: %+v", s)
+	}
+}
+
+func TestPersistentEngine_RedialsAfterPeerClose(t *testing.T) {
+	var accepted int32
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		atomic.AddInt32(&accepted, 1)
+		// Serve a single request, then drop the socket.
+		_ = serveFC3(c)
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+
+	for i := 0; i < 3; i++ {
+		if res := eng.Execute(context.Background(), req); res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if n := atomic.LoadInt32(&accepted); n != 3 {
+		t.Fatalf("expected 3 connections, got %d", n)
+	}
+	if s := eng.ConnStats(); s.Discarded != 2 {
+		t.Fatalf("expected 2 discarded connections, got %+v", s)
+	}
+}
+
+func TestPersistentEngine_LenientDiscardsPadding(t *testing.T) {
+	// Each response carries two padding bytes counted by its MBAP
+	// length but not by the FC 3 layout.
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		req := make([]byte, 12)
+		for {
+			if _, err := io.ReadFull(c, req); err != nil {
+				return
+			}
+			resp := append(fc3Response(req), 0, 0)
+			resp[5] += 2
+			if _, err := c.Write(resp); err != nil {
+				return
+			}
+		}
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr}
+	defer eng.Close()
+
+	for i := uint16(0); i < 3; i++ {
+		req := Request{UnitID: 1, FunctionCode: 3, Address: i, Quantity: 2, Timeout: time.Second}
+		if res := eng.Execute(context.Background(), req); res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+	}
+	if s := eng.ConnStats(); s.Dials != 1 || s.Discarded != 0 {
+		t.Fatalf("padding broke the connection: %+v", s)
+	}
+}
+
+// startWriteDropper answers the first request of each connection as
+// FC3, then reads the next request, counts it when it is a write and
+// closes without answering, as a device that applied a write just
+// before the link dropped.
+func startWriteDropper(t *testing.T, writes *int32) string {
+	return startModbusTCPResponder(t, func(c net.Conn) {
+		if serveFC3(c) != nil {
+			return
+		}
+		req := make([]byte, 12)
+		if _, err := io.ReadFull(c, req); err != nil {
+			return
+		}
+		if req[7] == 6 {
+			atomic.AddInt32(writes, 1)
+		}
+	})
+}
+
+func TestPersistentEngine_DoesNotResendWriteAfterBrokenConn(t *testing.T) {
+	var writes int32
+	eng := &PersistentEngine{TargetAddr: startWriteDropper(t, &writes)}
+	defer eng.Close()
+
+	read := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+	if res := eng.Execute(context.Background(), read); res.Err != nil {
+		t.Fatalf("read failed: %v", res.Err)
+	}
+
+	write := Request{UnitID: 1, FunctionCode: 6, Address: 1, Value: 5, Timeout: time.Second}
+	if res := eng.Execute(context.Background(), write); res.Err == nil {
+		t.Fatal("expected the broken connection to fail the write")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&writes); n != 1 {
+		t.Fatalf("write sent %d times, want 1", n)
+	}
+}
+
+func TestPersistentEngine_DialBackoff(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close() // nothing listens: dials are refused
+
+	eng := &PersistentEngine{
+		TargetAddr: addr,
+		Backoff:    50 * time.Millisecond,
+		MaxBackoff: 50 * time.Millisecond,
+	}
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected dial error")
+	}
+
+	start := time.Now()
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected dial error")
+	}
+	if waited := time.Since(start); waited < 40*time.Millisecond {
+		t.Fatalf("expected backoff before redial, waited %v", waited)
+	}
+}
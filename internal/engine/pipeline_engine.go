@@ -0,0 +1,270 @@
+// internal/engine/pipeline_engine.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// errPipelineTimeout is returned when no response matched a TxID in time.
+// It satisfies net.Error so callers classify it like a socket timeout.
+var errPipelineTimeout error = pipelineTimeout{}
+
+type pipelineTimeout struct{}
+
+func (pipelineTimeout) Error() string   { return "pipeline: response timeout" }
+func (pipelineTimeout) Timeout() bool   { return true }
+func (pipelineTimeout) Temporary() bool { return true }
+
+// PipelineStats describes how a device handled pipelined transactions.
+type PipelineStats struct {
+	MaxInFlight uint64 // highest number of outstanding transactions seen
+	OutOfOrder  uint64 // responses that overtook an earlier request
+	Unmatched   uint64 // responses whose TxID had no waiter (late or unknown)
+}
+
+// PipelineEngine writes up to Depth requests on one connection without
+// waiting for responses. A reader goroutine matches responses to callers
+// by MBAP transaction id, so replies may arrive in any order.
+//
+// Responses are always framed by the MBAP length field (strict framing).
+// A transport error fails every outstanding request and the connection
+// is redialed on the next Execute.
+type PipelineEngine struct {
+	TargetAddr string
+	Depth      int
+
+	once   sync.Once
+	window chan struct{}
+
+	mu  sync.Mutex
+	cur *pipe
+
+	stats connTracker
+
+	inFlight    int64
+	maxInFlight uint64
+	outOfOrder  uint64
+	unmatched   uint64
+}
+
+// pipe is one pipelined connection and its outstanding transactions.
+// All fields except conn are guarded by PipelineEngine.mu.
+type pipe struct {
+	conn    *client.Connection
+	tx      *client.Request
+	pending map[uint16]chan pipeReply
+	lastTx  uint16
+	closed  bool
+}
+
+type pipeReply struct {
+	frame []byte // [UnitID][FC][Data...]
+	err   error
+}
+
+// noDeadline lets the reader goroutine block between responses.
+type noDeadline struct {
+	conn *client.Connection
+}
+
+func (r noDeadline) ReadFull(b []byte) error {
+	return r.conn.ReadFullUntil(b, time.Time{})
+}
+
+func (e *PipelineEngine) Execute(ctx context.Context, req Request) Result {
+	start := time.Now()
+
+	e.once.Do(e.init)
+
+	select {
+	case e.window <- struct{}{}:
+	case <-ctx.Done():
+		return newResult(req, start, nil, &CanceledError{Err: ctx.Err()})
+	}
+	defer func() { <-e.window }()
+
+	p, txID, frame, reply, err := e.send(ctx, req)
+	if err != nil {
+		return newResult(req, start, nil, ctxErr(ctx, err))
+	}
+
+	n := atomic.AddInt64(&e.inFlight, 1)
+	defer atomic.AddInt64(&e.inFlight, -1)
+	for {
+		m := atomic.LoadUint64(&e.maxInFlight)
+		if uint64(n) <= m || atomic.CompareAndSwapUint64(&e.maxInFlight, m, uint64(n)) {
+			break
+		}
+	}
+
+	timer := time.NewTimer(effectiveTimeout(ctx, req.Timeout))
+	defer timer.Stop()
+
+	select {
+	case r := <-reply:
+		if r.err != nil {
+			return newResult(req, start, nil, r.err)
+		}
+		if err := client.ValidateFrame(r.frame, req.FunctionCode); err != nil {
+			return newResult(req, start, nil, err)
+		}
+		pdu := r.frame[1:]
+		if err := client.ValidateEcho(pdu, frame[7:]); err != nil {
+			return newResult(req, start, nil, err)
+		}
+		return newResult(req, start, pdu, nil)
+
+	case <-timer.C:
+		e.abandon(p, txID)
+		return newResult(req, start, nil, ctxErr(ctx, errPipelineTimeout))
+
+	case <-ctx.Done():
+		e.abandon(p, txID)
+		return newResult(req, start, nil, &CanceledError{Err: ctx.Err()})
+	}
+}
+
+func (e *PipelineEngine) init() {
+	depth := e.Depth
+	if depth <= 0 {
+		depth = 1
+	}
+	e.window = make(chan struct{}, depth)
+}
+
+// send registers a waiter and writes the request frame.
+// Writes are serialized so frames never interleave on the socket.
+func (e *PipelineEngine) send(ctx context.Context, req Request) (*pipe, uint16, []byte, chan pipeReply, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cur == nil {
+		conn, err := client.DialContext(ctx, e.TargetAddr, effectiveTimeout(ctx, req.Timeout))
+		if err != nil {
+			return nil, 0, nil, nil, err
+		}
+		e.stats.dialed()
+		e.cur = &pipe{
+			conn:    conn,
+			tx:      client.NewRequest(),
+			pending: make(map[uint16]chan pipeReply),
+		}
+		go e.readLoop(e.cur)
+	} else {
+		e.stats.reused()
+	}
+
+	p := e.cur
+
+	frame, err := buildFrame(p.tx, req)
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	txID := p.tx.TxID()
+
+	reply := make(chan pipeReply, 1)
+	p.pending[txID] = reply
+
+	if err := p.conn.Write(frame); err != nil {
+		delete(p.pending, txID)
+		e.stats.dropped()
+		e.breakLocked(p, err)
+		return nil, 0, nil, nil, err
+	}
+
+	return p, txID, frame, reply, nil
+}
+
+// readLoop delivers responses to waiters until the connection fails.
+func (e *PipelineEngine) readLoop(p *pipe) {
+	r := noDeadline{conn: p.conn}
+
+	for {
+		buf := make([]byte, 260) // max Modbus TCP ADU
+		txID, frame, err := client.ReadFrame(r, buf)
+		if err != nil {
+			e.mu.Lock()
+			if !p.closed {
+				e.stats.dropped()
+			}
+			e.breakLocked(p, err)
+			e.mu.Unlock()
+			return
+		}
+
+		e.mu.Lock()
+		reply, ok := p.pending[txID]
+		if ok {
+			delete(p.pending, txID)
+			if int16(txID-p.lastTx) < 0 {
+				atomic.AddUint64(&e.outOfOrder, 1)
+			} else {
+				p.lastTx = txID
+			}
+		}
+		e.mu.Unlock()
+
+		if !ok {
+			atomic.AddUint64(&e.unmatched, 1)
+			continue
+		}
+		reply <- pipeReply{frame: frame}
+	}
+}
+
+// breakLocked closes p and fails all outstanding requests.
+// Caller must hold e.mu.
+func (e *PipelineEngine) breakLocked(p *pipe, err error) {
+	if p.closed {
+		return
+	}
+	p.closed = true
+	_ = p.conn.Close()
+
+	for txID, reply := range p.pending {
+		reply <- pipeReply{err: fmt.Errorf("pipeline: connection lost: %w", err)}
+		delete(p.pending, txID)
+	}
+	if e.cur == p {
+		e.cur = nil
+	}
+}
+
+// abandon forgets a waiter; a later response for txID counts as unmatched.
+func (e *PipelineEngine) abandon(p *pipe, txID uint16) {
+	e.mu.Lock()
+	delete(p.pending, txID)
+	e.mu.Unlock()
+}
+
+// ConnStats returns a snapshot of connection counters.
+func (e *PipelineEngine) ConnStats() ConnStats {
+	return e.stats.snapshot()
+}
+
+// PipelineStats returns a snapshot of pipelining counters.
+func (e *PipelineEngine) PipelineStats() PipelineStats {
+	return PipelineStats{
+		MaxInFlight: atomic.LoadUint64(&e.maxInFlight),
+		OutOfOrder:  atomic.LoadUint64(&e.outOfOrder),
+		Unmatched:   atomic.LoadUint64(&e.unmatched),
+	}
+}
+
+// Close closes the connection and fails outstanding requests.
+func (e *PipelineEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cur != nil {
+		e.breakLocked(e.cur, errors.New("engine closed"))
+	}
+	return nil
+}
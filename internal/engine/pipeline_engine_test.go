@@ -0,0 +1,131 @@
+// internal/engine/pipeline_engine_test.go
+package engine
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestPipelineEngine_OutOfOrderResponses(t *testing.T) {
+	const depth = 4
+
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		for {
+			// Collect a full window, then answer in reverse order.
+			reqs := make([][]byte, depth)
+			for i := range reqs {
+				reqs[i] = make([]byte, 12)
+				if _, err := io.ReadFull(c, reqs[i]); err != nil {
+					return
+				}
+			}
+			for i := depth - 1; i >= 0; i-- {
+				if _, err := c.Write(fc3Response(reqs[i])); err != nil {
+					return
+				}
+			}
+		}
+	})
+
+	eng := &PipelineEngine{TargetAddr: addr, Depth: depth}
+	defer eng.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, depth*2)
+	for i := 0; i < depth*2; i++ {
+		wg.Add(1)
+		go func(addr uint16) {
+			defer wg.Done()
+			req := Request{UnitID: 1, FunctionCode: 3, Address: addr, Quantity: 1, Timeout: time.Second}
+			res := eng.Execute(context.Background(), req)
+			if res.Err != nil {
+				errs <- res.Err
+				return
+			}
+			values, err := format.DecodeReadValues(res.Raw, 3, 1)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if values[0] != addr {
+				t.Errorf("address %d: got value %d (response routed to wrong caller)", addr, values[0])
+			}
+		}(uint16(100 + i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ps := eng.PipelineStats()
+	if ps.MaxInFlight != depth {
+		t.Fatalf("expected %d in flight, got %+v", depth, ps)
+	}
+	if ps.OutOfOrder == 0 {
+		t.Fatalf("expected out-of-order responses, got %+v", ps)
+	}
+	if cs := eng.ConnStats(); cs.Dials != 1 {
+		t.Fatalf("expected a single connection, got %+v", cs)
+	}
+}
+
+func TestPipelineEngine_ConnectionLossFailsOutstanding(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		req := make([]byte, 12)
+		_, _ = io.ReadFull(c, req)
+		// Close without answering.
+	})
+
+	eng := &PipelineEngine{TargetAddr: addr, Depth: 2}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 2 * time.Second}
+
+	start := time.Now()
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected error after connection loss")
+	}
+	if time.Since(start) > time.Second {
+		t.Fatalf("outstanding request was not failed promptly")
+	}
+	if cs := eng.ConnStats(); cs.Discarded != 1 {
+		t.Fatalf("expected discarded connection, got %+v", cs)
+	}
+}
+
+func TestPipelineEngine_TimeoutThenLateResponse(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		req := make([]byte, 12)
+		if _, err := io.ReadFull(c, req); err != nil {
+			return
+		}
+		time.Sleep(60 * time.Millisecond)
+		_, _ = c.Write(fc3Response(req))
+		for serveFC3(c) == nil {
+		}
+	})
+
+	eng := &PipelineEngine{TargetAddr: addr, Depth: 1}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: 20 * time.Millisecond}
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected timeout")
+	}
+
+	req.Timeout = time.Second
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("second request failed: %v", res.Err)
+	}
+	if ps := eng.PipelineStats(); ps.Unmatched != 1 {
+		t.Fatalf("expected one unmatched late response, got %+v", ps)
+	}
+}
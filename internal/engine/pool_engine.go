@@ -0,0 +1,129 @@
+// internal/engine/pool_engine.go
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// PoolEngine holds up to Size connections to TargetAddr and hands one
+// to each in-flight Execute. Callers beyond Size wait for a free
+// connection, so the device never sees more than Size TCP sessions.
+//
+// Connections are dialed lazily and kept idle between requests.
+// A connection that fails with a transport error is discarded.
+type PoolEngine struct {
+	TargetAddr string
+	Strict     bool
+	Transport  client.Transport
+	Size       int
+
+	once  sync.Once
+	slots chan struct{}
+
+	mu   sync.Mutex
+	idle []*session
+
+	stats      connTracker
+	handshakes tlsTracker
+}
+
+func (e *PoolEngine) Execute(ctx context.Context, req Request) Result {
+	start := time.Now()
+
+	e.once.Do(e.init)
+
+	// Acquire a slot: bounds open connections to Size.
+	select {
+	case e.slots <- struct{}{}:
+	case <-ctx.Done():
+		return newResult(req, start, nil, &CanceledError{Err: ctx.Err()})
+	}
+	defer func() { <-e.slots }()
+
+	s := e.get()
+	reused := s != nil
+	if reused {
+		e.stats.reused()
+	} else {
+		var err error
+		if s, err = e.dial(ctx, req.Timeout); err != nil {
+			return newResult(req, start, nil, err)
+		}
+	}
+
+	redial := func() (*session, error) { return e.dial(ctx, req.Timeout) }
+
+	s, raw, err := exchange(ctx, s, reused, req, e.Strict, &e.stats, redial)
+	if s != nil {
+		e.put(s)
+	}
+
+	return newResult(req, start, raw, err)
+}
+
+func (e *PoolEngine) init() {
+	size := e.Size
+	if size <= 0 {
+		size = 1
+	}
+	e.slots = make(chan struct{}, size)
+}
+
+func (e *PoolEngine) dial(ctx context.Context, timeout time.Duration) (*session, error) {
+	conn, err := client.Open(ctx, e.TargetAddr, e.Transport, effectiveTimeout(ctx, timeout))
+	if err != nil {
+		return nil, ctxErr(ctx, err)
+	}
+	e.stats.dialed()
+	e.handshakes.observe(conn)
+	s := newSession(conn)
+	s.stats = &e.stats
+	return s, nil
+}
+
+// get pops the most recently used idle session, or nil.
+func (e *PoolEngine) get() *session {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := len(e.idle)
+	if n == 0 {
+		return nil
+	}
+	s := e.idle[n-1]
+	e.idle = e.idle[:n-1]
+	return s
+}
+
+func (e *PoolEngine) put(s *session) {
+	e.mu.Lock()
+	e.idle = append(e.idle, s)
+	e.mu.Unlock()
+}
+
+// ConnStats returns a snapshot of pool counters.
+func (e *PoolEngine) ConnStats() ConnStats {
+	return e.stats.snapshot()
+}
+
+// TLSInfo returns the most recent TLS handshake, or nil without TLS.
+func (e *PoolEngine) TLSInfo() *client.TLSInfo {
+	return e.handshakes.last()
+}
+
+// Close releases all idle connections.
+// In-flight requests keep their connection until they return.
+func (e *PoolEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, s := range e.idle {
+		s.close()
+	}
+	e.idle = nil
+	return nil
+}
@@ -0,0 +1,131 @@
+// internal/engine/pool_engine_test.go
+package engine
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolEngine_BoundsConcurrentConnections(t *testing.T) {
+	var open, peak int32
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		n := atomic.AddInt32(&open, 1)
+		defer atomic.AddInt32(&open, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		for {
+			time.Sleep(time.Millisecond) // keep requests overlapping
+			if serveFC3(c) != nil {
+				return
+			}
+		}
+	})
+
+	eng := &PoolEngine{TargetAddr: addr, Size: 3}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 2, Timeout: time.Second}
+
+	var wg sync.WaitGroup
+	var failed int32
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10; i++ {
+				if res := eng.Execute(context.Background(), req); res.Err != nil {
+					atomic.AddInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if failed != 0 {
+		t.Fatalf("%d requests failed", failed)
+	}
+	if p := atomic.LoadInt32(&peak); p > 3 {
+		t.Fatalf("expected at most 3 connections, saw %d", p)
+	}
+
+	s := eng.ConnStats()
+	if s.Dials > 3 || s.Dials+s.Reuses != 100 {
+		t.Fatalf("unexpected pool stats: %+v", s)
+	}
+}
+
+func TestPoolEngine_DiscardsBrokenConnection(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		// One answer per connection, then close.
+		_ = serveFC3(c)
+	})
+
+	eng := &PoolEngine{TargetAddr: addr, Size: 1}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+	for i := 0; i < 3; i++ {
+		if res := eng.Execute(context.Background(), req); res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if s := eng.ConnStats(); s.Dials != 3 || s.Discarded != 2 {
+		t.Fatalf("unexpected pool stats: %+v", s)
+	}
+}
+
+func TestPoolEngine_DoesNotResendWriteAfterBrokenConn(t *testing.T) {
+	var writes int32
+	eng := &PoolEngine{TargetAddr: startWriteDropper(t, &writes), Size: 1}
+	defer eng.Close()
+
+	read := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+	if res := eng.Execute(context.Background(), read); res.Err != nil {
+		t.Fatalf("read failed: %v", res.Err)
+	}
+
+	write := Request{UnitID: 1, FunctionCode: 6, Address: 1, Value: 5, Timeout: time.Second}
+	if res := eng.Execute(context.Background(), write); res.Err == nil {
+		t.Fatal("expected the broken connection to fail the write")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if n := atomic.LoadInt32(&writes); n != 1 {
+		t.Fatalf("write sent %d times, want 1", n)
+	}
+}
+
+func TestPoolEngine_WaitHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		<-release
+		_ = serveFC3(c)
+	})
+	defer close(release)
+
+	eng := &PoolEngine{TargetAddr: addr, Size: 1}
+	defer eng.Close()
+
+	req := Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second}
+
+	// Occupy the only slot.
+	go eng.Execute(context.Background(), req)
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if res := eng.Execute(ctx, req); res.Err == nil {
+		t.Fatalf("expected error while waiting for a free connection")
+	}
+}
@@ -0,0 +1,92 @@
+// internal/engine/read_write_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+// pduServer reads one request, reports its PDU on got, and replies
+// with the PDU returned by answer.
+func pduServer(got chan<- []byte, answer func(req []byte) []byte) func(net.Conn) {
+	return func(c net.Conn) {
+		hdr := make([]byte, 7)
+		if _, err := io.ReadFull(c, hdr); err != nil {
+			return
+		}
+		pdu := make([]byte, binary.BigEndian.Uint16(hdr[4:6])-1)
+		if _, err := io.ReadFull(c, pdu); err != nil {
+			return
+		}
+		got <- pdu
+
+		resp := answer(pdu)
+		out := make([]byte, 7+len(resp))
+		copy(out, hdr)
+		binary.BigEndian.PutUint16(out[4:6], uint16(1+len(resp)))
+		copy(out[7:], resp)
+		_, _ = c.Write(out)
+	}
+}
+
+func TestModbusEngine_MaskWriteRegister(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func(req []byte) []byte {
+		return req // FC 22 echoes the request
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 22, Address: 4, AndMask: 0x00F2, OrMask: 0x0025, Timeout: time.Second}
+
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("mask write failed: %v", res.Err)
+	}
+
+	want := []byte{22, 0x00, 0x04, 0x00, 0xF2, 0x00, 0x25}
+	if pdu := <-got; !bytes.Equal(pdu, want) {
+		t.Fatalf("unexpected PDU % x, want % x", pdu, want)
+	}
+}
+
+func TestModbusEngine_ReadWriteMultipleRegisters(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, pduServer(got, func(req []byte) []byte {
+		return []byte{23, 4, 0x00, 0xFE, 0x0A, 0xCD}
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr, Strict: true}
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 23,
+		Address:      3,
+		Quantity:     2,
+		WriteAddress: 14,
+		Values:       []uint16{0x00FF, 0x00FF, 0x00FF},
+		Timeout:      time.Second,
+	}
+
+	res := eng.Execute(context.Background(), req)
+	if res.Err != nil {
+		t.Fatalf("read/write failed: %v", res.Err)
+	}
+
+	want := []byte{23, 0x00, 0x03, 0x00, 0x02, 0x00, 0x0E, 0x00, 0x03, 6, 0x00, 0xFF, 0x00, 0xFF, 0x00, 0xFF}
+	if pdu := <-got; !bytes.Equal(pdu, want) {
+		t.Fatalf("unexpected PDU % x, want % x", pdu, want)
+	}
+
+	values, err := format.DecodeReadValues(res.Raw, 23, 2)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if values[0] != 0x00FE || values[1] != 0x0ACD {
+		t.Fatalf("unexpected values %#v", values)
+	}
+}
@@ -0,0 +1,77 @@
+// internal/engine/rtu_over_tcp_test.go
+package engine
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+func TestPoolEngine_RTUOverTCP(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) { serveRTU(c, 9, nil) })
+
+	eng := &PoolEngine{
+		TargetAddr: addr,
+		Size:       2,
+		Transport:  client.Transport{Framing: client.FramingRTU},
+	}
+	defer eng.Close()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			req := Request{UnitID: 9, FunctionCode: 3, Address: uint16(i), Quantity: 3, Timeout: time.Second}
+			res := eng.Execute(context.Background(), req)
+			if res.Err != nil {
+				errs <- res.Err
+				return
+			}
+			values, err := format.DecodeReadValues(res.Raw, 3, 3)
+			if err != nil || values[0] != uint16(i) || values[2] != uint16(i+2) {
+				errs <- errors.New("unexpected values")
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("rtu over tcp: %v", err)
+	}
+}
+
+func TestModbusEngine_RTUOverTCPException(t *testing.T) {
+	addr := startModbusTCPResponder(t, func(c net.Conn) { serveRTU(c, 1, nil) })
+
+	eng := &ModbusEngine{TargetAddr: addr, Transport: client.Transport{Framing: client.FramingRTU}}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Address: 150, Quantity: 1, Timeout: time.Second})
+
+	if me, ok := client.IsModbusException(res.Err); !ok || me.Code != 2 {
+		t.Fatalf("expected exception 2, got %v", res.Err)
+	}
+}
+
+func TestModbusEngine_RTUOverTCPWrongUnit(t *testing.T) {
+	// A converter that forwards a reply from another unit.
+	addr := startModbusTCPResponder(t, func(c net.Conn) {
+		serveRTU(c, 1, func(adu []byte) []byte {
+			return client.EncodeRTU(5, adu[1:len(adu)-2])
+		})
+	})
+
+	eng := &ModbusEngine{TargetAddr: addr, Transport: client.Transport{Framing: client.FramingRTU}}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second})
+	if res.Err == nil {
+		t.Fatalf("expected unit id mismatch")
+	}
+}
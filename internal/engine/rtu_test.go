@@ -0,0 +1,144 @@
+// internal/engine/rtu_test.go
+
+//go:build linux
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+// openPTY returns the master side of a new pseudo-terminal and the
+// path of its slave, which the engine opens as a serial device.
+func openPTY(t *testing.T) (*os.File, string) {
+	t.Helper()
+
+	m, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("no pseudo-terminal support: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+
+	rc, err := m.SyscallConn()
+	if err != nil {
+		t.Fatalf("pty: %v", err)
+	}
+
+	var unlock int32
+	var n uint32
+	var ioErr error
+	_ = rc.Control(func(fd uintptr) {
+		if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); e != 0 {
+			ioErr = e
+			return
+		}
+		if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); e != 0 {
+			ioErr = e
+		}
+	})
+	if ioErr != nil {
+		t.Fatalf("pty: %v", ioErr)
+	}
+
+	return m, fmt.Sprintf("/dev/pts/%d", n)
+}
+
+func rtuTransport(dev string) client.Transport {
+	return client.Transport{
+		Framing: client.FramingRTU,
+		Serial:  &client.SerialConfig{Device: dev, Baud: 19200, DataBits: 8, Parity: 'E', StopBits: 1},
+	}
+}
+
+func TestPersistentEngine_RTUOverPTY(t *testing.T) {
+	m, dev := openPTY(t)
+	go serveRTU(m, 7, nil)
+
+	eng := &PersistentEngine{Transport: rtuTransport(dev)}
+	defer eng.Close()
+
+	for i := 0; i < 3; i++ {
+		req := Request{UnitID: 7, FunctionCode: 3, Address: uint16(10 * i), Quantity: 4, Timeout: time.Second}
+
+		res := eng.Execute(context.Background(), req)
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		values, err := format.DecodeReadValues(res.Raw, 3, 4)
+		if err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if values[0] != uint16(10*i) || values[3] != uint16(10*i+3) {
+			t.Fatalf("unexpected values %v", values)
+		}
+	}
+
+	// Exceptions keep the line open and are reported as such.
+	res := eng.Execute(context.Background(), Request{UnitID: 7, FunctionCode: 3, Address: 200, Quantity: 1, Timeout: time.Second})
+	if me, ok := client.IsModbusException(res.Err); !ok || me.Code != 2 {
+		t.Fatalf("expected exception 2, got %v", res.Err)
+	}
+
+	if s := eng.ConnStats(); s.Dials != 1 || s.Discarded != 0 {
+		t.Fatalf("expected one open serial line, got %+v", s)
+	}
+}
+
+func TestModbusEngine_RTUBadCRC(t *testing.T) {
+	m, dev := openPTY(t)
+	go serveRTU(m, 1, func(adu []byte) []byte {
+		adu[len(adu)-1] ^= 0xFF
+		return adu
+	})
+
+	eng := &ModbusEngine{Transport: rtuTransport(dev)}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Quantity: 2, Timeout: time.Second})
+
+	var ce *client.ChecksumError
+	if !errors.As(res.Err, &ce) || ce.Kind != "crc" {
+		t.Fatalf("expected crc error, got %v", res.Err)
+	}
+	if _, ok := client.IsModbusException(res.Err); ok {
+		t.Fatalf("crc error must not be a modbus exception")
+	}
+}
+
+func TestModbusEngine_RTUSilentUnitTimesOut(t *testing.T) {
+	m, dev := openPTY(t)
+	go serveRTU(m, 1, nil)
+
+	eng := &ModbusEngine{Transport: rtuTransport(dev)}
+	res := eng.Execute(context.Background(), Request{UnitID: 2, FunctionCode: 3, Quantity: 1, Timeout: 100 * time.Millisecond})
+	if !isTimeout(res.Err) {
+		t.Fatalf("expected timeout for an absent unit, got %v", res.Err)
+	}
+}
+
+func TestModbusEngine_ASCIIOverPTY(t *testing.T) {
+	m, dev := openPTY(t)
+	go serveASCII(m, 2, nil)
+
+	eng := &ModbusEngine{Transport: client.Transport{
+		Framing: client.FramingASCII,
+		Serial:  &client.SerialConfig{Device: dev, Baud: 9600, DataBits: 7, Parity: 'E', StopBits: 1},
+	}}
+
+	res := eng.Execute(context.Background(), Request{UnitID: 2, FunctionCode: 3, Address: 7, Quantity: 2, Timeout: time.Second})
+	if res.Err != nil {
+		t.Fatalf("ascii over pty failed: %v", res.Err)
+	}
+	values, err := format.DecodeReadValues(res.Raw, 3, 2)
+	if err != nil || values[0] != 7 || values[1] != 8 {
+		t.Fatalf("unexpected values %v (%v)", values, err)
+	}
+}
@@ -0,0 +1,149 @@
+// internal/engine/session.go
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// session is one Modbus connection plus its transaction counter.
+// Every engine executes requests through roundTrip, so there is
+// exactly one request/response path regardless of connection mode.
+type session struct {
+	conn *client.Connection
+	tx   *client.Request
+
+	// stats, when set, receives UDP datagram counters; answered holds
+	// recent UDP transaction ids.
+	stats    *connTracker
+	answered txWindow
+}
+
+func newSession(conn *client.Connection) *session {
+	return &session{
+		conn: conn,
+		tx:   client.NewRequest(),
+	}
+}
+
+// roundTrip writes one request and reads its response.
+// It returns protocol-level bytes only (no decoding here).
+//
+// Socket deadlines never outlive ctx, and cancelling ctx aborts any
+// blocked read or write. The session is unusable after cancellation.
+func (s *session) roundTrip(ctx context.Context, req Request, strict bool) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, &CanceledError{Err: err}
+	}
+
+	stop := watchContext(ctx, s.conn)
+	raw, err := s.transact(req, effectiveTimeout(ctx, req.Timeout), strict)
+	if stop() {
+		return nil, &CanceledError{Err: ctx.Err()}
+	}
+	return raw, ctxErr(ctx, err)
+}
+
+func (s *session) transact(req Request, timeout time.Duration, strict bool) ([]byte, error) {
+	s.conn.SetTimeout(timeout)
+
+	// Build request frame
+	frame, err := buildFrame(s.tx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var pdu []byte
+	switch {
+	case s.conn.Datagram():
+		pdu, err = s.transactUDP(frame, timeout)
+	case s.conn.Framing() == client.FramingRTU:
+		pdu, err = s.transactRTU(frame)
+	case s.conn.Framing() == client.FramingASCII:
+		pdu, err = s.transactASCII(frame)
+	default:
+		pdu, err = s.transactTCP(frame, strict)
+	}
+	if err != nil || pdu == nil {
+		return nil, err
+	}
+
+	// Writes must echo the request.
+	if err := client.ValidateEcho(pdu, frame[7:]); err != nil {
+		return nil, err
+	}
+
+	return pdu, nil
+}
+
+// transactTCP sends an MBAP frame and reads the matching response.
+func (s *session) transactTCP(frame []byte, strict bool) ([]byte, error) {
+	expectedTxID := s.tx.TxID()
+
+	if err := s.conn.Write(frame); err != nil {
+		return nil, err
+	}
+
+	// Read MBAP header (7 bytes)
+	hdr := make([]byte, 7)
+	if err := s.conn.ReadFull(hdr); err != nil {
+		return nil, err
+	}
+
+	// Parse response into PDU buffer
+	parser := client.NewResponseParser(strict)
+
+	// Oversize buffer is fine; the parser returns the exact PDU.
+	pduBuf := make([]byte, 512)
+
+	return parser.Parse(s.conn, expectedTxID, frame[7], hdr, pduBuf)
+}
+
+// transactRTU re-frames the MBAP request as RTU and reads the reply.
+// Broadcasts (unit 0) are never answered.
+func (s *session) transactRTU(frame []byte) ([]byte, error) {
+	unitID, pdu := frame[6], frame[7:]
+
+	if err := s.conn.Write(client.EncodeRTU(unitID, pdu)); err != nil {
+		return nil, err
+	}
+	if unitID == 0 {
+		return nil, nil
+	}
+
+	return client.ReadRTU(s.conn.Frame(), make([]byte, 512), unitID, pdu[0])
+}
+
+// transactASCII re-frames the MBAP request as ASCII and reads the reply.
+// Broadcasts (unit 0) are never answered.
+func (s *session) transactASCII(frame []byte) ([]byte, error) {
+	unitID, pdu := frame[6], frame[7:]
+
+	if err := s.conn.Write(client.EncodeASCII(unitID, pdu)); err != nil {
+		return nil, err
+	}
+	if unitID == 0 {
+		return nil, nil
+	}
+
+	return client.ReadASCII(s.conn.Frame(), unitID, pdu[0])
+}
+
+func (s *session) close() {
+	_ = s.conn.Close()
+}
+
+// newResult fills a Result from the request it answers.
+func newResult(req Request, start time.Time, raw []byte, err error) Result {
+	return Result{
+		UnitID:       req.UnitID,
+		FunctionCode: req.FunctionCode,
+		Address:      req.Address,
+		Quantity:     req.Quantity,
+		Raw:          raw,
+		Duration:     time.Since(start),
+		Err:          err,
+	}
+}
@@ -0,0 +1,25 @@
+// internal/engine/tls.go
+package engine
+
+import (
+	"sync/atomic"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// tlsTracker remembers the most recent TLS handshake of an engine's
+// connections. Safe for concurrent use.
+type tlsTracker struct {
+	v atomic.Value // *client.TLSInfo
+}
+
+func (t *tlsTracker) observe(c *client.Connection) {
+	if info := c.TLSInfo(); info != nil {
+		t.v.Store(info)
+	}
+}
+
+func (t *tlsTracker) last() *client.TLSInfo {
+	info, _ := t.v.Load().(*client.TLSInfo)
+	return info
+}
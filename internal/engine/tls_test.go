@@ -0,0 +1,275 @@
+// internal/engine/tls_test.go
+package engine
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+// testPKI is a throwaway CA with one server and one client certificate.
+type testPKI struct {
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	server tls.Certificate
+	dir    string // PEM files: ca.pem, client.pem, client-key.pem
+}
+
+func newTestPKI(t *testing.T, serverRole string) *testPKI {
+	t.Helper()
+
+	p := &testPKI{dir: t.TempDir()}
+
+	caKey := newKey(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "rdxbus test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("create CA: %v", err)
+	}
+	p.ca, _ = x509.ParseCertificate(caDER)
+	p.caKey = caKey
+	writePEM(t, filepath.Join(p.dir, "ca.pem"), "CERTIFICATE", caDER)
+
+	var ext []pkix.Extension
+	if serverRole != "" {
+		v, _ := asn1.MarshalWithParams(serverRole, "utf8")
+		ext = append(ext, pkix.Extension{Id: client.RoleOID, Value: v})
+	}
+	srvDER, srvKey := p.issue(t, 2, "plc-01", x509.ExtKeyUsageServerAuth, ext)
+	p.server = tls.Certificate{Certificate: [][]byte{srvDER}, PrivateKey: srvKey}
+
+	cliDER, cliKey := p.issue(t, 3, "engineer", x509.ExtKeyUsageClientAuth, nil)
+	writePEM(t, filepath.Join(p.dir, "client.pem"), "CERTIFICATE", cliDER)
+	keyDER, err := x509.MarshalECPrivateKey(cliKey)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	writePEM(t, filepath.Join(p.dir, "client-key.pem"), "EC PRIVATE KEY", keyDER)
+
+	return p
+}
+
+func (p *testPKI) issue(t *testing.T, serial int64, cn string, usage x509.ExtKeyUsage, ext []pkix.Extension) ([]byte, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:    big.NewInt(serial),
+		Subject:         pkix.Name{CommonName: cn, Organization: []string{"Plant"}},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{usage},
+		IPAddresses:     []net.IP{net.ParseIP("127.0.0.1")},
+		ExtraExtensions: ext,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, p.ca, &key.PublicKey, p.caKey)
+	if err != nil {
+		t.Fatalf("issue %s: %v", cn, err)
+	}
+	return der, key
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// startTLSResponder serves FC3 over mutually authenticated TLS and
+// reports the client certificate subject of each handshake on peers.
+func startTLSResponder(t *testing.T, p *testPKI, peers chan<- string) string {
+	t.Helper()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(p.ca)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{p.server},
+		ClientCAs:    roots,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer c.Close()
+
+				tc := c.(*tls.Conn)
+				if err := tc.Handshake(); err != nil {
+					return
+				}
+				if peers != nil {
+					peers <- tc.ConnectionState().PeerCertificates[0].Subject.CommonName
+				}
+				for {
+					req := make([]byte, 12)
+					if _, err := io.ReadFull(tc, req); err != nil {
+						return
+					}
+					if _, err := tc.Write(fc3Response(req)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = ln.Close()
+		wg.Wait()
+	})
+
+	return ln.Addr().String()
+}
+
+func TestPersistentEngine_TLS(t *testing.T) {
+	p := newTestPKI(t, "Operator")
+	peers := make(chan string, 1)
+	addr := startTLSResponder(t, p, peers)
+
+	cfg, err := client.TLSOptions{
+		CAFile:   filepath.Join(p.dir, "ca.pem"),
+		CertFile: filepath.Join(p.dir, "client.pem"),
+		KeyFile:  filepath.Join(p.dir, "client-key.pem"),
+	}.Config()
+	if err != nil {
+		t.Fatalf("tls config: %v", err)
+	}
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{TLS: cfg}}
+	defer eng.Close()
+
+	for i := uint16(0); i < 3; i++ {
+		res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Address: 5 * i, Quantity: 2, Timeout: time.Second})
+		if res.Err != nil {
+			t.Fatalf("request %d failed: %v", i, res.Err)
+		}
+		values, err := format.DecodeReadValues(res.Raw, 3, 2)
+		if err != nil || values[0] != 5*i {
+			t.Fatalf("unexpected values %v (%v)", values, err)
+		}
+	}
+
+	if cn := <-peers; cn != "engineer" {
+		t.Fatalf("server saw client %q", cn)
+	}
+
+	info := eng.TLSInfo()
+	if info == nil {
+		t.Fatal("no handshake info")
+	}
+	if info.Subject != "CN=plc-01,O=Plant" || info.Role != "Operator" {
+		t.Fatalf("unexpected handshake info %+v", info)
+	}
+	if s := eng.ConnStats(); s.Dials != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestModbusEngine_TLSNoRole(t *testing.T) {
+	p := newTestPKI(t, "")
+	addr := startTLSResponder(t, p, nil)
+
+	cfg, err := client.TLSOptions{
+		CAFile:     filepath.Join(p.dir, "ca.pem"),
+		CertFile:   filepath.Join(p.dir, "client.pem"),
+		KeyFile:    filepath.Join(p.dir, "client-key.pem"),
+		MinVersion: "1.3",
+	}.Config()
+	if err != nil {
+		t.Fatalf("tls config: %v", err)
+	}
+
+	eng := &ModbusEngine{TargetAddr: addr, Transport: client.Transport{TLS: cfg}}
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second})
+	if res.Err != nil {
+		t.Fatalf("request failed: %v", res.Err)
+	}
+
+	info := eng.TLSInfo()
+	if info == nil || info.Version != "TLS 1.3" || info.Role != "" {
+		t.Fatalf("unexpected handshake info %+v", info)
+	}
+}
+
+func TestModbusEngine_TLSRejected(t *testing.T) {
+	p := newTestPKI(t, "Operator")
+	addr := startTLSResponder(t, p, nil)
+
+	// Without a client certificate the server refuses the handshake.
+	noCert, err := client.TLSOptions{CAFile: filepath.Join(p.dir, "ca.pem")}.Config()
+	if err != nil {
+		t.Fatalf("tls config: %v", err)
+	}
+	// Without the CA the server certificate does not verify.
+	noCA, err := client.TLSOptions{
+		CertFile: filepath.Join(p.dir, "client.pem"),
+		KeyFile:  filepath.Join(p.dir, "client-key.pem"),
+	}.Config()
+	if err != nil {
+		t.Fatalf("tls config: %v", err)
+	}
+
+	for name, cfg := range map[string]*tls.Config{"no client cert": noCert, "unknown CA": noCA} {
+		eng := &ModbusEngine{TargetAddr: addr, Transport: client.Transport{TLS: cfg}}
+		res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Quantity: 1, Timeout: time.Second})
+		if res.Err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		// With TLS 1.3 a missing client certificate is refused after
+		// the client's side of the handshake; an unverified server
+		// must never be reported.
+		if cfg == noCA && eng.TLSInfo() != nil {
+			t.Fatalf("%s: unverified server reported", name)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// internal/engine/udp.go
+package engine
+
+import (
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+)
+
+// transactUDP sends an MBAP frame as one datagram and waits for the
+// datagram with the same transaction id, resending the request after
+// each timeout up to the connection's retry count. Each attempt waits
+// at most timeout, however many stray datagrams arrive meanwhile.
+//
+// Datagrams for other transaction ids belong to earlier requests:
+// they are counted as duplicates when that request was already
+// answered, as late otherwise, and dropped. Datagrams that are not
+// MBAP frames are counted as malformed and dropped.
+func (s *session) transactUDP(frame []byte, timeout time.Duration) ([]byte, error) {
+	txID := s.tx.TxID()
+	buf := make([]byte, 512)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			s.stats.retransmitted()
+		}
+		if err := s.conn.Write(frame); err != nil {
+			return nil, err
+		}
+
+		deadline := time.Now().Add(timeout)
+		for {
+			n, err := s.conn.ReadDatagramUntil(buf, deadline)
+			if err != nil {
+				if isTimeout(err) && attempt < s.conn.Retries() {
+					break // resend
+				}
+				return nil, err
+			}
+
+			got, body, err := client.ParseDatagram(buf[:n])
+			if err != nil {
+				s.stats.malformed()
+				continue
+			}
+			if got != txID {
+				if s.answered.has(got) {
+					s.stats.duplicate()
+				} else {
+					s.stats.late()
+				}
+				continue
+			}
+
+			s.answered.add(txID)
+			if err := client.ValidateFrame(body, frame[7]); err != nil {
+				return nil, err
+			}
+			return body[1:], nil
+		}
+	}
+}
+
+// txWindow remembers the most recent answered transaction ids so a
+// repeated response can be told from a late one.
+type txWindow struct {
+	ids  [64]uint16
+	n    int // ids used, up to len(ids)
+	next int
+}
+
+func (w *txWindow) add(id uint16) {
+	w.ids[w.next] = id
+	w.next = (w.next + 1) % len(w.ids)
+	if w.n < len(w.ids) {
+		w.n++
+	}
+}
+
+func (w *txWindow) has(id uint16) bool {
+	for _, v := range w.ids[:w.n] {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// keepAfter reports whether a session that failed with err can serve
+// the next request. A datagram socket holds no partial response, so a
+// lost datagram (timeout) leaves it usable and late replies countable.
+func keepAfter(s *session, err error) bool {
+	if _, ok := client.IsModbusException(err); ok {
+		return true
+	}
+	return s.conn.Datagram() && isTimeout(err) && !IsCanceled(err)
+}
@@ -0,0 +1,205 @@
+// internal/engine/udp_test.go
+package engine
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+// startUDPResponder answers each FC3 request datagram with the
+// datagrams returned by reply (registers equal their address).
+// n counts requests received so far, starting at 1.
+func startUDPResponder(t *testing.T, reply func(n int, resp []byte) [][]byte) string {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		buf := make([]byte, 512)
+		for n := 1; ; n++ {
+			m, peer, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if m != 12 {
+				continue
+			}
+			for _, d := range reply(n, fc3Response(buf[:m])) {
+				_, _ = pc.WriteTo(d, peer)
+			}
+		}
+	}()
+
+	t.Cleanup(func() {
+		_ = pc.Close()
+		wg.Wait()
+	})
+
+	return pc.LocalAddr().String()
+}
+
+func udpRead(t *testing.T, eng Engine, addr uint16) error {
+	t.Helper()
+
+	res := eng.Execute(context.Background(), Request{UnitID: 1, FunctionCode: 3, Address: addr, Quantity: 2, Timeout: 100 * time.Millisecond})
+	if res.Err != nil {
+		return res.Err
+	}
+	values, err := format.DecodeReadValues(res.Raw, 3, 2)
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	if values[0] != addr || values[1] != addr+1 {
+		t.Fatalf("unexpected values %v", values)
+	}
+	return nil
+}
+
+func TestPersistentEngine_UDP(t *testing.T) {
+	addr := startUDPResponder(t, func(_ int, resp []byte) [][]byte { return [][]byte{resp} })
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{UDP: true}}
+	defer eng.Close()
+
+	for i := uint16(0); i < 5; i++ {
+		if err := udpRead(t, eng, 10*i); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if s := eng.ConnStats(); s.Dials != 1 || s.Reuses != 4 || s.Retransmits != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestPersistentEngine_UDPRetransmit(t *testing.T) {
+	// The first datagram of every request is lost.
+	addr := startUDPResponder(t, func(n int, resp []byte) [][]byte {
+		if n%2 == 1 {
+			return nil
+		}
+		return [][]byte{resp}
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{UDP: true, Retries: 1}}
+	defer eng.Close()
+
+	for i := uint16(0); i < 3; i++ {
+		if err := udpRead(t, eng, i); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if s := eng.ConnStats(); s.Retransmits != 3 || s.Late != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestPersistentEngine_UDPDuplicate(t *testing.T) {
+	addr := startUDPResponder(t, func(_ int, resp []byte) [][]byte { return [][]byte{resp, resp} })
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{UDP: true}}
+	defer eng.Close()
+
+	for i := uint16(0); i < 2; i++ {
+		if err := udpRead(t, eng, i); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	if s := eng.ConnStats(); s.Duplicates != 1 || s.Late != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestPersistentEngine_UDPLate(t *testing.T) {
+	// The first response arrives after its request gave up.
+	addr := startUDPResponder(t, func(n int, resp []byte) [][]byte {
+		if n == 1 {
+			time.Sleep(150 * time.Millisecond)
+		}
+		return [][]byte{resp}
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{UDP: true}}
+	defer eng.Close()
+
+	if err := udpRead(t, eng, 0); !isTimeout(err) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if err := udpRead(t, eng, 7); err != nil {
+		t.Fatalf("second request failed: %v", err)
+	}
+
+	if s := eng.ConnStats(); s.Late != 1 || s.Dials != 1 || s.Discarded != 0 {
+		t.Fatalf("a lost datagram must keep the socket and count the late reply, got %+v", s)
+	}
+}
+
+func TestPersistentEngine_UDPDropsMalformed(t *testing.T) {
+	addr := startUDPResponder(t, func(_ int, resp []byte) [][]byte {
+		bad := append([]byte(nil), resp...)
+		bad[5]++ // MBAP length past the datagram
+		return [][]byte{{0xFF}, bad, resp}
+	})
+
+	eng := &PersistentEngine{TargetAddr: addr, Transport: client.Transport{UDP: true}}
+	defer eng.Close()
+
+	if err := udpRead(t, eng, 3); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if s := eng.ConnStats(); s.Malformed != 2 || s.Discarded != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
+
+func TestPersistentEngine_UDPStrayTrafficKeepsTimeout(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	defer pc.Close()
+
+	// Never answer; send a stray datagram every 20ms instead, each
+	// well inside the 100ms timeout.
+	go func() {
+		buf := make([]byte, 512)
+		_, peer, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		for i := 0; i < 50; i++ {
+			time.Sleep(20 * time.Millisecond)
+			if _, err := pc.WriteTo([]byte("noise"), peer); err != nil {
+				return
+			}
+		}
+	}()
+
+	eng := &PersistentEngine{TargetAddr: pc.LocalAddr().String(), Transport: client.Transport{UDP: true}}
+	defer eng.Close()
+
+	start := time.Now()
+	if err := udpRead(t, eng, 0); !isTimeout(err) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if d := time.Since(start); d > 300*time.Millisecond {
+		t.Fatalf("request took %v with a 100ms timeout", d)
+	}
+	if s := eng.ConnStats(); s.Malformed == 0 {
+		t.Fatalf("stray datagrams not counted: %+v", s)
+	}
+}
@@ -0,0 +1,101 @@
+// internal/engine/write_multiple_test.go
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// multiWriteServer reads one FC 15/16 request, reports it on got
+// and answers with the address/quantity echo.
+func multiWriteServer(got chan<- []byte) func(net.Conn) {
+	return func(c net.Conn) {
+		hdr := make([]byte, 7)
+		if _, err := io.ReadFull(c, hdr); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint16(hdr[4:6])-1)
+		if _, err := io.ReadFull(c, body); err != nil {
+			return
+		}
+		got <- append(append([]byte(nil), hdr...), body...)
+
+		resp := make([]byte, 12)
+		copy(resp, hdr)
+		binary.BigEndian.PutUint16(resp[4:6], 6)
+		copy(resp[7:12], body[:5])
+		_, _ = c.Write(resp)
+	}
+}
+
+func TestModbusEngine_WriteMultipleRegisters(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, multiWriteServer(got))
+
+	eng := &ModbusEngine{TargetAddr: addr, Strict: true}
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 16,
+		Address:      200,
+		Values:       []uint16{0x1234, 0xABCD, 7},
+		Timeout:      time.Second,
+	}
+
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("write failed: %v", res.Err)
+	}
+
+	frame := <-got
+	want := []byte{
+		16,        // FC
+		0x00, 200, // address
+		0x00, 3, // quantity
+		6, // byte count
+		0x12, 0x34, 0xAB, 0xCD, 0x00, 0x07,
+	}
+	if !bytes.Equal(frame[7:], want) {
+		t.Fatalf("unexpected PDU % x, want % x", frame[7:], want)
+	}
+	if binary.BigEndian.Uint16(frame[4:6]) != uint16(1+len(want)) {
+		t.Fatalf("bad MBAP length in % x", frame)
+	}
+}
+
+func TestModbusEngine_WriteMultipleCoils_PacksLSBFirst(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, multiWriteServer(got))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{
+		UnitID:       1,
+		FunctionCode: 15,
+		Address:      19,
+		// Spec example: coils 20..29 = 1 0 1 1 0 0 1 1 1 0
+		Values:  []uint16{1, 0, 1, 1, 0, 0, 1, 1, 1, 0},
+		Timeout: time.Second,
+	}
+
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("write failed: %v", res.Err)
+	}
+
+	frame := <-got
+	want := []byte{15, 0x00, 19, 0x00, 10, 2, 0xCD, 0x01}
+	if !bytes.Equal(frame[7:], want) {
+		t.Fatalf("unexpected PDU % x, want % x", frame[7:], want)
+	}
+}
+
+func TestModbusEngine_WriteMultipleRejectsEmpty(t *testing.T) {
+	eng := &ModbusEngine{TargetAddr: startModbusTCPResponder(t, silentServer)}
+	req := Request{UnitID: 1, FunctionCode: 16, Timeout: time.Second}
+
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected error for FC 16 without values")
+	}
+}
@@ -0,0 +1,85 @@
+// internal/engine/write_test.go
+package engine
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// echoServer answers one 12-byte write request with its PDU, letting
+// mutate alter the echoed PDU first. The request is sent on got.
+func echoServer(got chan<- []byte, mutate func(pdu []byte)) func(net.Conn) {
+	return func(c net.Conn) {
+		req := make([]byte, 12)
+		if _, err := io.ReadFull(c, req); err != nil {
+			return
+		}
+		got <- append([]byte(nil), req...)
+
+		resp := append([]byte(nil), req...)
+		if mutate != nil {
+			mutate(resp[7:])
+		}
+		_, _ = c.Write(resp)
+	}
+}
+
+func TestModbusEngine_WriteSingleRegister(t *testing.T) {
+	for _, strict := range []bool{false, true} {
+		got := make(chan []byte, 1)
+		addr := startModbusTCPResponder(t, echoServer(got, nil))
+
+		eng := &ModbusEngine{TargetAddr: addr, Strict: strict}
+		req := Request{UnitID: 1, FunctionCode: 6, Address: 40, Value: 1234, Timeout: time.Second}
+
+		res := eng.Execute(context.Background(), req)
+		if res.Err != nil {
+			t.Fatalf("strict=%v: write failed: %v", strict, res.Err)
+		}
+
+		frame := <-got
+		if frame[7] != 6 ||
+			binary.BigEndian.Uint16(frame[8:10]) != 40 ||
+			binary.BigEndian.Uint16(frame[10:12]) != 1234 {
+			t.Fatalf("strict=%v: unexpected request frame % x", strict, frame)
+		}
+		if len(res.Raw) != 5 || res.Raw[0] != 6 {
+			t.Fatalf("strict=%v: unexpected raw PDU % x", strict, res.Raw)
+		}
+	}
+}
+
+func TestModbusEngine_WriteSingleCoil_EncodesOn(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, echoServer(got, nil))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 5, Address: 3, Value: 1, Timeout: time.Second}
+
+	if res := eng.Execute(context.Background(), req); res.Err != nil {
+		t.Fatalf("write failed: %v", res.Err)
+	}
+
+	frame := <-got
+	if v := binary.BigEndian.Uint16(frame[10:12]); v != 0xFF00 {
+		t.Fatalf("expected coil ON as 0xFF00, got %#04x", v)
+	}
+}
+
+func TestModbusEngine_WriteEchoMismatch(t *testing.T) {
+	got := make(chan []byte, 1)
+	addr := startModbusTCPResponder(t, echoServer(got, func(pdu []byte) {
+		pdu[4]++ // device reports a different value
+	}))
+
+	eng := &ModbusEngine{TargetAddr: addr}
+	req := Request{UnitID: 1, FunctionCode: 6, Address: 1, Value: 10, Timeout: time.Second}
+
+	if res := eng.Execute(context.Background(), req); res.Err == nil {
+		t.Fatalf("expected echo mismatch error")
+	}
+}
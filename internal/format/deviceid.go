@@ -0,0 +1,77 @@
+// internal/format/deviceid.go
+package format
+
+import "fmt"
+
+// DeviceObject is one Read Device Identification object.
+type DeviceObject struct {
+	ID    uint8
+	Value string
+}
+
+// DeviceID is one decoded FC 43 / MEI 14 response.
+type DeviceID struct {
+	Code         uint8
+	Conformity   uint8
+	MoreFollows  bool
+	NextObjectID uint8
+	Objects      []DeviceObject
+}
+
+// DecodeDeviceID decodes a Read Device Identification response PDU
+// [43][14][Code][Conformity][MoreFollows][NextObjectID][NumObjects]
+// followed by NumObjects x [ObjectID][Length][Value...].
+func DecodeDeviceID(pdu []byte) (DeviceID, error) {
+	if len(pdu) < 7 {
+		return DeviceID{}, fmt.Errorf("device id response too short: %d bytes", len(pdu))
+	}
+	if pdu[0] != 43 || pdu[1] != 14 {
+		return DeviceID{}, fmt.Errorf("not a device id response: fc=%d mei=%d", pdu[0], pdu[1])
+	}
+
+	id := DeviceID{
+		Code:         pdu[2],
+		Conformity:   pdu[3],
+		MoreFollows:  pdu[4] == 0xFF,
+		NextObjectID: pdu[5],
+	}
+
+	n := int(pdu[6])
+	data := pdu[7:]
+	for i := 0; i < n; i++ {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return DeviceID{}, fmt.Errorf("device id object %d truncated", i)
+		}
+		size := int(data[1])
+		id.Objects = append(id.Objects, DeviceObject{
+			ID:    data[0],
+			Value: string(data[2 : 2+size]),
+		})
+		data = data[2+size:]
+	}
+
+	return id, nil
+}
+
+var deviceObjectNames = [...]string{
+	"VendorName",
+	"ProductCode",
+	"MajorMinorRevision",
+	"VendorUrl",
+	"ProductName",
+	"ModelName",
+	"UserApplicationName",
+}
+
+// DeviceObjectName returns the standard name of a device id object.
+// Reserved and private objects are named by their id.
+func DeviceObjectName(id uint8) string {
+	switch {
+	case int(id) < len(deviceObjectNames):
+		return deviceObjectNames[id]
+	case id >= 0x80:
+		return fmt.Sprintf("Private 0x%02X", id)
+	default:
+		return fmt.Sprintf("Reserved 0x%02X", id)
+	}
+}
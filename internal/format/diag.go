@@ -0,0 +1,160 @@
+// internal/format/diag.go
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Diagnostics (FC 8) sub-functions used by rdxbus.
+const (
+	DiagReturnQueryData       = 0x00
+	DiagReturnDiagRegister    = 0x02
+	DiagClearCounters         = 0x0A
+	DiagBusMessageCount       = 0x0B
+	DiagBusCommErrorCount     = 0x0C
+	DiagBusExceptionCount     = 0x0D
+	DiagServerMessageCount    = 0x0E
+	DiagServerNoResponseCount = 0x0F
+	DiagServerNAKCount        = 0x10
+	DiagServerBusyCount       = 0x11
+	DiagBusCharOverrunCount   = 0x12
+)
+
+var diagNames = map[uint16]string{
+	0x00: "Return Query Data",
+	0x01: "Restart Communications Option",
+	0x02: "Return Diagnostic Register",
+	0x03: "Change ASCII Input Delimiter",
+	0x04: "Force Listen Only Mode",
+	0x0A: "Clear Counters and Diagnostic Register",
+	0x0B: "Return Bus Message Count",
+	0x0C: "Return Bus Communication Error Count",
+	0x0D: "Return Bus Exception Error Count",
+	0x0E: "Return Server Message Count",
+	0x0F: "Return Server No Response Count",
+	0x10: "Return Server NAK Count",
+	0x11: "Return Server Busy Count",
+	0x12: "Return Bus Character Overrun Count",
+	0x14: "Clear Overrun Counter and Flag",
+}
+
+// DiagName returns the name of an FC 8 sub-function.
+func DiagName(sub uint16) string {
+	if n, ok := diagNames[sub]; ok {
+		return n
+	}
+	return fmt.Sprintf("Sub-function 0x%04X", sub)
+}
+
+// CommEventCounter is a Get Comm Event Counter (FC 11) response.
+type CommEventCounter struct {
+	Status     uint16
+	EventCount uint16
+}
+
+// CommEventLog is a Get Comm Event Log (FC 12) response.
+// Events are ordered most recent first.
+type CommEventLog struct {
+	Status       uint16
+	EventCount   uint16
+	MessageCount uint16
+	Events       []byte
+}
+
+// ServerID is a Report Server ID (FC 17) response. The server id is
+// device specific; it is taken as the first data byte, followed by
+// the run indicator and any additional data.
+type ServerID struct {
+	ID         uint8
+	Running    bool
+	Additional []byte
+}
+
+// DecodeExceptionStatus decodes a Read Exception Status (FC 7)
+// response [7][Status].
+func DecodeExceptionStatus(pdu []byte) (uint8, error) {
+	if err := expectPDU(pdu, 7, 2); err != nil {
+		return 0, err
+	}
+	return pdu[1], nil
+}
+
+// DecodeDiagnostics decodes a Diagnostics (FC 8) response
+// [8][SubFunction(2)][Data(2)].
+func DecodeDiagnostics(pdu []byte) (sub, data uint16, err error) {
+	if err := expectPDU(pdu, 8, 5); err != nil {
+		return 0, 0, err
+	}
+	return binary.BigEndian.Uint16(pdu[1:3]), binary.BigEndian.Uint16(pdu[3:5]), nil
+}
+
+// DecodeCommEventCounter decodes a Get Comm Event Counter (FC 11)
+// response [11][Status(2)][EventCount(2)].
+func DecodeCommEventCounter(pdu []byte) (CommEventCounter, error) {
+	if err := expectPDU(pdu, 11, 5); err != nil {
+		return CommEventCounter{}, err
+	}
+	return CommEventCounter{
+		Status:     binary.BigEndian.Uint16(pdu[1:3]),
+		EventCount: binary.BigEndian.Uint16(pdu[3:5]),
+	}, nil
+}
+
+// DecodeCommEventLog decodes a Get Comm Event Log (FC 12) response
+// [12][ByteCount][Status(2)][EventCount(2)][MessageCount(2)][Events...].
+func DecodeCommEventLog(pdu []byte) (CommEventLog, error) {
+	data, err := byteCountData(pdu, 12)
+	if err != nil {
+		return CommEventLog{}, err
+	}
+	if len(data) < 6 {
+		return CommEventLog{}, fmt.Errorf("comm event log too short: %d bytes", len(data))
+	}
+	return CommEventLog{
+		Status:       binary.BigEndian.Uint16(data[0:2]),
+		EventCount:   binary.BigEndian.Uint16(data[2:4]),
+		MessageCount: binary.BigEndian.Uint16(data[4:6]),
+		Events:       data[6:],
+	}, nil
+}
+
+// DecodeServerID decodes a Report Server ID (FC 17) response
+// [17][ByteCount][ServerID][RunIndicator][Additional...].
+func DecodeServerID(pdu []byte) (ServerID, error) {
+	data, err := byteCountData(pdu, 17)
+	if err != nil {
+		return ServerID{}, err
+	}
+	if len(data) < 2 {
+		return ServerID{}, fmt.Errorf("server id too short: %d bytes", len(data))
+	}
+	return ServerID{
+		ID:         data[0],
+		Running:    data[1] == 0xFF,
+		Additional: data[2:],
+	}, nil
+}
+
+// expectPDU checks the function code and minimum length of pdu.
+func expectPDU(pdu []byte, fc uint8, n int) error {
+	if len(pdu) < 1 || pdu[0] != fc {
+		return fmt.Errorf("function code %d not found in PDU", fc)
+	}
+	if len(pdu) < n {
+		return fmt.Errorf("fc %d response too short: %d bytes", fc, len(pdu))
+	}
+	return nil
+}
+
+// byteCountData returns the data of a [FC][ByteCount][Data...] PDU.
+func byteCountData(pdu []byte, fc uint8) ([]byte, error) {
+	if err := expectPDU(pdu, fc, 2); err != nil {
+		return nil, err
+	}
+	n := int(pdu[1])
+	if 2+n > len(pdu) {
+		return nil, fmt.Errorf("data exceeds PDU length")
+	}
+	return pdu[2 : 2+n], nil
+}
@@ -0,0 +1,64 @@
+// internal/format/filerecord.go
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DecodeFileRecords decodes a Read File Record (FC 20) response
+// [20][RespDataLen] followed by one group per sub-request
+// [FileRespLen][RefType=6][Data...]. It returns the registers of
+// each sub-request in request order.
+func DecodeFileRecords(pdu []byte) ([][]uint16, error) {
+	data, err := byteCountData(pdu, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	var out [][]uint16
+	for len(data) > 0 {
+		n := int(data[0]) // counts the reference type and the data
+		if n < 1 || 1+n > len(data) {
+			return nil, fmt.Errorf("file record %d: length %d exceeds response", len(out), n)
+		}
+		if data[1] != 6 {
+			return nil, fmt.Errorf("file record %d: reference type %d, expected 6", len(out), data[1])
+		}
+		if (n-1)%2 != 0 {
+			return nil, fmt.Errorf("file record %d: odd data length %d", len(out), n-1)
+		}
+
+		regs := make([]uint16, (n-1)/2)
+		for i := range regs {
+			regs[i] = binary.BigEndian.Uint16(data[2+2*i:])
+		}
+		out = append(out, regs)
+		data = data[1+n:]
+	}
+
+	return out, nil
+}
+
+// DecodeFIFO decodes a Read FIFO Queue (FC 24) response
+// [24][ByteCount(2)][FIFOCount(2)][Values...].
+func DecodeFIFO(pdu []byte) ([]uint16, error) {
+	if err := expectPDU(pdu, 24, 5); err != nil {
+		return nil, err
+	}
+
+	byteCount := int(binary.BigEndian.Uint16(pdu[1:3]))
+	count := int(binary.BigEndian.Uint16(pdu[3:5]))
+	if byteCount != 2+2*count {
+		return nil, fmt.Errorf("fifo bytecount mismatch: got %d expected %d", byteCount, 2+2*count)
+	}
+	if 3+byteCount > len(pdu) {
+		return nil, fmt.Errorf("data exceeds PDU length")
+	}
+
+	out := make([]uint16, count)
+	for i := range out {
+		out[i] = binary.BigEndian.Uint16(pdu[5+2*i:])
+	}
+	return out, nil
+}
@@ -0,0 +1,147 @@
+// internal/format/order.go
+package format
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Order is the byte order of a multi-register value, named by where
+// its bytes A (most significant) to D land on the wire. For 64-bit
+// values the same word and byte swaps apply to all four registers.
+type Order int
+
+const (
+	OrderABCD Order = iota // big-endian (the Modbus default)
+	OrderCDAB              // word swap
+	OrderBADC              // byte swap within each register
+	OrderDCBA              // little-endian: word and byte swap
+)
+
+var orderNames = []string{
+	OrderABCD: "ABCD",
+	OrderCDAB: "CDAB",
+	OrderBADC: "BADC",
+	OrderDCBA: "DCBA",
+}
+
+// Orders lists every byte order.
+func Orders() []Order {
+	return []Order{OrderABCD, OrderCDAB, OrderBADC, OrderDCBA}
+}
+
+// ParseOrder parses an order name such as "CDAB".
+func ParseOrder(s string) (Order, error) {
+	for o, name := range orderNames {
+		if strings.EqualFold(s, name) {
+			return Order(o), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown byte order %q (want %s)", s, strings.Join(orderNames, ", "))
+}
+
+func (o Order) String() string {
+	if o < 0 || int(o) >= len(orderNames) {
+		return fmt.Sprintf("Order(%d)", int(o))
+	}
+	return orderNames[o]
+}
+
+func (o Order) wordSwap() bool { return o == OrderCDAB || o == OrderDCBA }
+func (o Order) byteSwap() bool { return o == OrderBADC || o == OrderDCBA }
+
+// normalize returns raw as big-endian words, most significant first.
+func (o Order) normalize(raw []uint16) []uint16 {
+	out := make([]uint16, len(raw))
+	for i, w := range raw {
+		if o.byteSwap() {
+			w = w<<8 | w>>8
+		}
+		if o.wordSwap() {
+			out[len(raw)-1-i] = w
+		} else {
+			out[i] = w
+		}
+	}
+	return out
+}
+
+// DecodeAllOrders decodes regs as t under every order, in Orders()
+// order, so the plausible interpretation can be picked by eye.
+func DecodeAllOrders(start uint16, regs []uint16, t Type) ([][]Value, error) {
+	out := make([][]Value, 0, len(orderNames))
+	for _, o := range Orders() {
+		v, err := DecodeTyped(start, regs, t, o)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// GuessOrder picks the order under which the values of type t look
+// most like real measurements: first the most values that are finite
+// and within 1e-6..1e9 in magnitude (or zero), then the smallest
+// total distance from 1 in orders of magnitude. Ties go to the earlier
+// order, so big-endian wins when nothing stands out.
+func GuessOrder(regs []uint16, t Type) (Order, error) {
+	best, bestCount, bestSpread := OrderABCD, -1, 0.0
+	for _, o := range Orders() {
+		values, err := DecodeTyped(0, regs, t, o)
+		if err != nil {
+			return 0, err
+		}
+		count, spread := 0, 0.0
+		for _, v := range values {
+			f := toFloat(v.Value)
+			a := math.Abs(f)
+			if math.IsNaN(f) || math.IsInf(f, 0) || (a != 0 && (a < 1e-6 || a > 1e9)) {
+				continue
+			}
+			count++
+			if a != 0 {
+				spread += math.Abs(math.Log10(a))
+			}
+		}
+		if count > bestCount || (count == bestCount && spread < bestSpread) {
+			best, bestCount, bestSpread = o, count, spread
+		}
+	}
+	return best, nil
+}
+
+// asFloat converts a value returned by DecodeTyped to float64; ok is
+// false for non-numeric values (strings, bitfields).
+func asFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case uint16:
+		return float64(x), true
+	case int16:
+		return float64(x), true
+	case uint32:
+		return float64(x), true
+	case int32:
+		return float64(x), true
+	case float32:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	default:
+		return 0, false
+	}
+}
+
+// toFloat widens any decoded value to float64; NaN when it is not a
+// number.
+func toFloat(v any) float64 {
+	if x, ok := asFloat(v); ok {
+		return x
+	}
+	return math.NaN()
+}
@@ -0,0 +1,77 @@
+// internal/format/order_test.go
+package format
+
+import "testing"
+
+// 230.1 as float32 is the bytes 43 66 19 9A (A B C D).
+var float32Wire = map[Order][]uint16{
+	OrderABCD: {0x4366, 0x199A},
+	OrderCDAB: {0x199A, 0x4366},
+	OrderBADC: {0x6643, 0x9A19},
+	OrderDCBA: {0x9A19, 0x6643},
+}
+
+func TestDecodeTyped_Orders(t *testing.T) {
+	for o, regs := range float32Wire {
+		got, err := DecodeTyped(0, regs, TypeFloat32, o)
+		if err != nil {
+			t.Fatalf("%s: %v", o, err)
+		}
+		if got[0].Value != float32(230.1) {
+			t.Fatalf("%s: got %v", o, got[0].Value)
+		}
+	}
+
+	// 64-bit values swap all four words and the bytes inside them.
+	regs := []uint16{0x0800, 0x0700, 0x0600, 0x0500} // 0x0005000600070008 as DCBA
+	got, err := DecodeTyped(0, regs, TypeUint64, OrderDCBA)
+	if err != nil || got[0].Value != uint64(0x0005000600070008) {
+		t.Fatalf("uint64 DCBA: got %v, %v", got, err)
+	}
+
+	// Single registers are never reordered.
+	got, err = DecodeTyped(0, []uint16{0x0102}, TypeUint16, OrderDCBA)
+	if err != nil || got[0].Value != uint16(0x0102) {
+		t.Fatalf("uint16 DCBA: got %v, %v", got, err)
+	}
+}
+
+func TestDecodeAllOrders(t *testing.T) {
+	all, err := DecodeAllOrders(10, float32Wire[OrderBADC], TypeFloat32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || all[OrderBADC][0].Value != float32(230.1) || all[OrderBADC][0].Address != 10 {
+		t.Fatalf("unexpected result %v", all)
+	}
+	if all[OrderABCD][0].Value == float32(230.1) {
+		t.Fatal("ABCD must not decode BADC wire bytes to the same value")
+	}
+}
+
+func TestGuessOrder(t *testing.T) {
+	for o, regs := range float32Wire {
+		// Two values make the guess unambiguous.
+		got, err := GuessOrder(append(append([]uint16(nil), regs...), regs...), TypeFloat32)
+		if err != nil || got != o {
+			t.Fatalf("%s: guessed %s (%v)", o, got, err)
+		}
+	}
+
+	// int32 1000 as CDAB: 0x03E8 0x0000.
+	if got, _ := GuessOrder([]uint16{0x03E8, 0x0000}, TypeInt32); got != OrderCDAB {
+		t.Fatalf("int32: guessed %s", got)
+	}
+}
+
+func TestParseOrder(t *testing.T) {
+	for _, o := range Orders() {
+		got, err := ParseOrder(o.String())
+		if err != nil || got != o {
+			t.Fatalf("%s: got %v, %v", o, got, err)
+		}
+	}
+	if _, err := ParseOrder("ABDC"); err == nil {
+		t.Fatal("expected error for ABDC")
+	}
+}
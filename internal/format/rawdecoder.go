@@ -6,7 +6,7 @@ import (
 	"fmt"
 )
 
-// DecodeReadValues decodes Modbus read responses (FC 1–4)
+// DecodeReadValues decodes Modbus read responses (FC 1–4, 23)
 // from a raw PDU buffer.
 //
 // Supported layouts:
@@ -48,7 +48,7 @@ func DecodeReadValues(
 
 	switch functionCode {
 
-	case 3, 4:
+	case 3, 4, 23:
 		expected := int(quantity) * 2
 		if byteCount != expected {
 			return nil, fmt.Errorf(
@@ -0,0 +1,108 @@
+// internal/format/scale.go
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// SunSpecNotImplemented is the sunssf value of a scale register the
+// device does not implement.
+const SunSpecNotImplemented = 0x8000
+
+// Scaling turns a decoded register value into an engineering value:
+// value × Scale × 10^exponent + Offset, in Unit. The exponent comes
+// from a scale register, or is 0 without one. A zero Scaling is the
+// identity.
+type Scaling struct {
+	Scale  float64 // multiplier; 0 means 1
+	Offset float64
+	Unit   string
+}
+
+// IsZero reports whether s leaves values unchanged and unlabelled.
+func (s Scaling) IsZero() bool {
+	return (s.Scale == 0 || s.Scale == 1) && s.Offset == 0 && s.Unit == ""
+}
+
+// Apply scales a value returned by DecodeTyped. Only numeric and BCD
+// values can be scaled; a NaN or infinite float stays NaN or infinite.
+func (s Scaling) Apply(v any, exp int) (Engineering, error) {
+	x, ok := asFloat(v)
+	if !ok {
+		return Engineering{}, fmt.Errorf("%T value cannot be scaled", v)
+	}
+	if f, ok := v.(float32); ok {
+		// Start from the shortest decimal of the float32 (230.1), not
+		// its binary expansion (230.10000610351562).
+		x, _ = strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	}
+	if s.Scale != 0 {
+		x *= s.Scale
+	}
+	// Divide for negative exponents: 2351 / 10 is exactly 235.1, while
+	// 2351 * 0.1 is not.
+	if exp < 0 {
+		x /= math.Pow10(-exp)
+	} else {
+		x *= math.Pow10(exp)
+	}
+	x += s.Offset
+	return Engineering{Value: tidy(x), Unit: s.Unit}, nil
+}
+
+// ScaleExponent reads a SunSpec scale register (sunssf): a signed
+// power of ten between -10 and 10.
+func ScaleExponent(reg uint16) (int, error) {
+	if reg == SunSpecNotImplemented {
+		return 0, fmt.Errorf("exponent not implemented (0x8000)")
+	}
+	exp := int(int16(reg))
+	if exp < -10 || exp > 10 {
+		return 0, fmt.Errorf("exponent %d out of range -10..10", exp)
+	}
+	return exp, nil
+}
+
+// Engineering is a scaled value with its unit, e.g. "235.1 V".
+type Engineering struct {
+	Value float64
+	Unit  string
+}
+
+func (e Engineering) String() string {
+	v := strconv.FormatFloat(e.Value, 'g', -1, 64)
+	if e.Unit == "" {
+		return v
+	}
+	return v + " " + e.Unit
+}
+
+// MarshalJSON writes {"value":235.1,"unit":"V"}; the unit is omitted
+// when empty. JSON has no NaN or infinity, so those values, e.g. a
+// scaled float32 NaN, are written as null.
+func (e Engineering) MarshalJSON() ([]byte, error) {
+	var v *float64
+	if !math.IsNaN(e.Value) && !math.IsInf(e.Value, 0) {
+		v = &e.Value
+	}
+	return json.Marshal(struct {
+		Value *float64 `json:"value"`
+		Unit  string   `json:"unit,omitempty"`
+	}{v, e.Unit})
+}
+
+// tidy drops binary rounding noise such as 23.099999999999998 by
+// keeping 12 significant digits.
+func tidy(x float64) float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return x
+	}
+	v, err := strconv.ParseFloat(strconv.FormatFloat(x, 'g', 12, 64), 64)
+	if err != nil {
+		return x
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+// internal/format/scale_test.go
+package format
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestScaling_Apply(t *testing.T) {
+	cases := []struct {
+		s    Scaling
+		v    any
+		exp  int
+		want string
+	}{
+		{Scaling{Unit: "V"}, uint16(2351), -1, "235.1 V"},
+		{Scaling{Scale: 0.1, Unit: "V"}, uint16(2351), 0, "235.1 V"},
+		{Scaling{Scale: 0.01, Offset: -40, Unit: "°C"}, int16(6310), 0, "23.1 °C"},
+		{Scaling{Unit: "W"}, int32(-15), 2, "-1500 W"},
+		{Scaling{}, float32(1.5), 0, "1.5"},
+		{Scaling{Unit: "V"}, float32(230.1), 0, "230.1 V"},
+		{Scaling{Unit: "Wh"}, uint32(125678), 0, "125678 Wh"}, // BCD
+	}
+	for _, c := range cases {
+		got, err := c.s.Apply(c.v, c.exp)
+		if err != nil {
+			t.Fatalf("%v: %v", c.v, err)
+		}
+		if got.String() != c.want {
+			t.Fatalf("%v × %+v × 10^%d: got %q, want %q", c.v, c.s, c.exp, got, c.want)
+		}
+	}
+
+	if _, err := (Scaling{Unit: "V"}).Apply("text", 0); err == nil {
+		t.Fatal("expected error scaling a string")
+	}
+	if _, err := (Scaling{Unit: "V"}).Apply(Bits(5), 0); err == nil {
+		t.Fatal("expected error scaling a bitfield")
+	}
+}
+
+func TestScaling_ApplyNonFinite(t *testing.T) {
+	s := Scaling{Scale: 0.1, Offset: 1, Unit: "V"}
+	got, err := s.Apply(float32(math.NaN()), -1)
+	if err != nil || !math.IsNaN(got.Value) || got.Unit != "V" {
+		t.Fatalf("float32 NaN: got %+v, %v", got, err)
+	}
+	got, err = s.Apply(math.Inf(-1), 0)
+	if err != nil || !math.IsInf(got.Value, -1) {
+		t.Fatalf("-Inf: got %+v, %v", got, err)
+	}
+	got, err = s.Apply(float32(math.Inf(1)), 2)
+	if err != nil || !math.IsInf(got.Value, 1) {
+		t.Fatalf("float32 +Inf: got %+v, %v", got, err)
+	}
+}
+
+func TestScaleExponent(t *testing.T) {
+	if exp, err := ScaleExponent(0xFFFE); err != nil || exp != -2 {
+		t.Fatalf("got %d, %v", exp, err)
+	}
+	if exp, err := ScaleExponent(3); err != nil || exp != 3 {
+		t.Fatalf("got %d, %v", exp, err)
+	}
+	for _, reg := range []uint16{SunSpecNotImplemented, 11, 0xFFF5} {
+		if _, err := ScaleExponent(reg); err == nil {
+			t.Fatalf("0x%04X: expected error", reg)
+		}
+	}
+}
+
+func TestEngineering_JSON(t *testing.T) {
+	b, err := json.Marshal(Engineering{Value: 235.1, Unit: "V"})
+	if err != nil || string(b) != `{"value":235.1,"unit":"V"}` {
+		t.Fatalf("got %s, %v", b, err)
+	}
+	b, _ = json.Marshal(Engineering{Value: 2})
+	if string(b) != `{"value":2}` {
+		t.Fatalf("got %s", b)
+	}
+	for _, v := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		b, err = json.Marshal(Engineering{Value: v, Unit: "V"})
+		if err != nil || string(b) != `{"value":null,"unit":"V"}` {
+			t.Fatalf("%v: got %s, %v", v, b, err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// internal/format/text.go
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DecodeString reads registers as ASCII text, two characters per
+// register, high byte first (low byte first with byteSwap). Trailing
+// NUL padding is trimmed.
+func DecodeString(regs []uint16, byteSwap bool) string {
+	b := make([]byte, 0, 2*len(regs))
+	for _, w := range regs {
+		if byteSwap {
+			b = append(b, byte(w), byte(w>>8))
+		} else {
+			b = append(b, byte(w>>8), byte(w))
+		}
+	}
+	return strings.TrimRight(string(b), "\x00")
+}
+
+// DecodeBCD reads packed BCD digits, four per register, most
+// significant first. Nibbles above 9 are an error.
+func DecodeBCD(words []uint16) (uint64, error) {
+	var v uint64
+	for _, w := range words {
+		for shift := 12; shift >= 0; shift -= 4 {
+			d := (w >> uint(shift)) & 0xF
+			if d > 9 {
+				return 0, fmt.Errorf("0x%04X is not BCD", w)
+			}
+			v = v*10 + uint64(d)
+		}
+	}
+	return v, nil
+}
+
+// Bits is a bitfield register. It prints as binary in groups of four,
+// most significant bit first.
+type Bits uint16
+
+// Bit reports whether bit n (0 = least significant) is set.
+func (b Bits) Bit(n uint) bool {
+	return b&(1<<n) != 0
+}
+
+func (b Bits) String() string {
+	s := fmt.Sprintf("%016b", uint16(b))
+	return s[0:4] + " " + s[4:8] + " " + s[8:12] + " " + s[12:16]
+}
+
+// BitName names one bit of a bitfield register.
+type BitName struct {
+	Bit  uint // 0 = least significant
+	Name string
+}
+
+// ParseBitLayout parses named bits such as "0:running,1:fault,15:alarm".
+func ParseBitLayout(s string) ([]BitName, error) {
+	var out []BitName
+	seen := map[uint]bool{}
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		num, name, ok := strings.Cut(part, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("bit %q: want bit:name", part)
+		}
+		n, err := strconv.ParseUint(strings.TrimSpace(num), 10, 8)
+		if err != nil || n > 15 {
+			return nil, fmt.Errorf("bit %q: bit must be 0..15", part)
+		}
+		if seen[uint(n)] {
+			return nil, fmt.Errorf("bit %d named twice", n)
+		}
+		seen[uint(n)] = true
+		out = append(out, BitName{Bit: uint(n), Name: name})
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no bits named")
+	}
+	return out, nil
+}
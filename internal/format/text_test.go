@@ -0,0 +1,63 @@
+// internal/format/text_test.go
+package format
+
+import "testing"
+
+func TestDecodeString(t *testing.T) {
+	regs := []uint16{0x5244, 0x5842, 0x5553, 0x0000} // "RDXBUS" + NUL padding
+	if got := DecodeString(regs, false); got != "RDXBUS" {
+		t.Fatalf("got %q", got)
+	}
+
+	swapped := []uint16{0x4452, 0x4258, 0x5355}
+	if got := DecodeString(swapped, true); got != "RDXBUS" {
+		t.Fatalf("swapped: got %q", got)
+	}
+
+	got, err := DecodeTyped(7, swapped, TypeString, OrderBADC)
+	if err != nil || len(got) != 1 || got[0].Value != "RDXBUS" || got[0].Address != 7 {
+		t.Fatalf("DecodeTyped string: got %v, %v", got, err)
+	}
+}
+
+func TestDecodeBCD(t *testing.T) {
+	v, err := DecodeBCD([]uint16{0x1234})
+	if err != nil || v != 1234 {
+		t.Fatalf("got %d, %v", v, err)
+	}
+
+	got, err := DecodeTyped(0, []uint16{0x5678, 0x0012}, TypeBCD32, OrderCDAB)
+	if err != nil || got[0].Value != uint32(125678) {
+		t.Fatalf("bcd32 CDAB: got %v, %v", got, err)
+	}
+
+	if _, err := DecodeBCD([]uint16{0x12A4}); err == nil {
+		t.Fatal("expected error for nibble above 9")
+	}
+}
+
+func TestBits(t *testing.T) {
+	b := Bits(0x8005)
+	if !b.Bit(0) || b.Bit(1) || !b.Bit(2) || !b.Bit(15) {
+		t.Fatalf("unexpected bits %s", b)
+	}
+	if s := b.String(); s != "1000 0000 0000 0101" {
+		t.Fatalf("got %q", s)
+	}
+}
+
+func TestParseBitLayout(t *testing.T) {
+	bits, err := ParseBitLayout("0:running, 1:fault,15:alarm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bits) != 3 || bits[1] != (BitName{Bit: 1, Name: "fault"}) || bits[2].Bit != 15 {
+		t.Fatalf("got %v", bits)
+	}
+
+	for _, s := range []string{"", "16:x", "0:a,0:b", "x:a", "3:"} {
+		if _, err := ParseBitLayout(s); err == nil {
+			t.Fatalf("%q: expected error", s)
+		}
+	}
+}
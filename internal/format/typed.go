@@ -0,0 +1,162 @@
+// internal/format/typed.go
+package format
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Type is how consecutive registers are interpreted. The byte order
+// of multi-register values is given separately as an Order.
+// Numeric types come first; string, BCD and bitfield types follow.
+type Type int
+
+const (
+	TypeUint16 Type = iota // one register as read (the default)
+	TypeInt16
+	TypeUint32
+	TypeInt32
+	TypeFloat32
+	TypeUint64
+	TypeInt64
+	TypeFloat64
+	TypeString   // ASCII text over all registers read, two bytes each
+	TypeBCD16    // four packed BCD digits
+	TypeBCD32    // eight packed BCD digits over two registers
+	TypeBitfield // one register of individual bits
+)
+
+var typeNames = []string{
+	TypeUint16:   "uint16",
+	TypeInt16:    "int16",
+	TypeUint32:   "uint32",
+	TypeInt32:    "int32",
+	TypeFloat32:  "float32",
+	TypeUint64:   "uint64",
+	TypeInt64:    "int64",
+	TypeFloat64:  "float64",
+	TypeString:   "string",
+	TypeBCD16:    "bcd16",
+	TypeBCD32:    "bcd32",
+	TypeBitfield: "bitfield",
+}
+
+// TypeNames lists the accepted type names in Type order.
+func TypeNames() []string {
+	return append([]string(nil), typeNames...)
+}
+
+// ParseType parses a type name such as "float32".
+func ParseType(s string) (Type, error) {
+	for t, name := range typeNames {
+		if strings.EqualFold(s, name) {
+			return Type(t), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown type %q (want %s)", s, strings.Join(typeNames, ", "))
+}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+	return typeNames[t]
+}
+
+// Numeric reports whether t is an integer or floating point type.
+func (t Type) Numeric() bool {
+	return t <= TypeFloat64
+}
+
+// Registers is the number of 16-bit registers one value occupies,
+// or 0 for a string, which takes all registers read.
+func (t Type) Registers() int {
+	switch t {
+	case TypeString:
+		return 0
+	case TypeUint32, TypeInt32, TypeFloat32, TypeBCD32:
+		return 2
+	case TypeUint64, TypeInt64, TypeFloat64:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// Value is one decoded value and the registers it came from.
+type Value struct {
+	Address uint16   // first register
+	Raw     []uint16 // registers as read
+	Value   any      // int16, uint16, int32, uint32, float32, int64, uint64, float64, string or Bits
+}
+
+// DecodeTyped interprets regs, read from address start, as
+// consecutive values of type t. Multi-register values are assembled
+// in byte order o; single registers are always taken as read.
+// A string is one value; it honours only the byte swap of o.
+func DecodeTyped(start uint16, regs []uint16, t Type, o Order) ([]Value, error) {
+	if t == TypeString {
+		return []Value{{Address: start, Raw: regs, Value: DecodeString(regs, o.byteSwap())}}, nil
+	}
+
+	n := t.Registers()
+	if len(regs)%n != 0 {
+		return nil, fmt.Errorf("%d registers do not divide into %s values of %d registers", len(regs), t, n)
+	}
+
+	out := make([]Value, 0, len(regs)/n)
+	for i := 0; i < len(regs); i += n {
+		raw := regs[i : i+n]
+		words := raw
+		if n > 1 {
+			words = o.normalize(raw)
+		}
+		v, err := typedValue(words, t)
+		if err != nil {
+			return nil, fmt.Errorf("register %d: %w", start+uint16(i), err)
+		}
+		out = append(out, Value{
+			Address: start + uint16(i),
+			Raw:     raw,
+			Value:   v,
+		})
+	}
+	return out, nil
+}
+
+// typedValue converts len(words) == t.Registers() words, most
+// significant first.
+func typedValue(words []uint16, t Type) (any, error) {
+	var u uint64
+	for _, w := range words {
+		u = u<<16 | uint64(w)
+	}
+
+	switch t {
+	case TypeInt16:
+		return int16(u), nil
+	case TypeUint32:
+		return uint32(u), nil
+	case TypeInt32:
+		return int32(u), nil
+	case TypeFloat32:
+		return math.Float32frombits(uint32(u)), nil
+	case TypeUint64:
+		return u, nil
+	case TypeInt64:
+		return int64(u), nil
+	case TypeFloat64:
+		return math.Float64frombits(u), nil
+	case TypeBCD16:
+		v, err := DecodeBCD(words)
+		return uint16(v), err
+	case TypeBCD32:
+		v, err := DecodeBCD(words)
+		return uint32(v), err
+	case TypeBitfield:
+		return Bits(u), nil
+	default:
+		return uint16(u), nil
+	}
+}
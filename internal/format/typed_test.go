@@ -0,0 +1,72 @@
+// internal/format/typed_test.go
+package format
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTyped(t *testing.T) {
+	cases := []struct {
+		typ  Type
+		regs []uint16
+		want []any
+	}{
+		{TypeUint16, []uint16{0xFFFF, 1}, []any{uint16(0xFFFF), uint16(1)}},
+		{TypeInt16, []uint16{0xFFFE, 0x7FFF}, []any{int16(-2), int16(32767)}},
+		{TypeUint32, []uint16{0x0001, 0x0002}, []any{uint32(0x00010002)}},
+		{TypeInt32, []uint16{0xFFFF, 0xFFFF}, []any{int32(-1)}},
+		{TypeFloat32, []uint16{0x4366, 0x199A}, []any{float32(230.1)}},
+		{TypeUint64, []uint16{0, 0, 0x0001, 0}, []any{uint64(0x10000)}},
+		{TypeInt64, []uint16{0xFFFF, 0xFFFF, 0xFFFF, 0xFFF6}, []any{int64(-10)}},
+		{TypeFloat64, float64Regs(-1.5), []any{float64(-1.5)}},
+	}
+
+	for _, c := range cases {
+		got, err := DecodeTyped(100, c.regs, c.typ, OrderABCD)
+		if err != nil {
+			t.Fatalf("%s: %v", c.typ, err)
+		}
+		if len(got) != len(c.want) {
+			t.Fatalf("%s: got %d values want %d", c.typ, len(got), len(c.want))
+		}
+		for i, v := range got {
+			if v.Value != c.want[i] {
+				t.Fatalf("%s[%d]: got %v (%T) want %v", c.typ, i, v.Value, v.Value, c.want[i])
+			}
+			if v.Address != 100+uint16(i*c.typ.Registers()) {
+				t.Fatalf("%s[%d]: address %d", c.typ, i, v.Address)
+			}
+		}
+		if !reflect.DeepEqual(got[0].Raw, c.regs[:c.typ.Registers()]) {
+			t.Fatalf("%s: raw %v", c.typ, got[0].Raw)
+		}
+	}
+}
+
+func TestDecodeTyped_RejectsPartialValue(t *testing.T) {
+	if _, err := DecodeTyped(0, []uint16{1, 2, 3}, TypeFloat32, OrderABCD); err == nil {
+		t.Fatal("expected error for 3 registers as float32")
+	}
+}
+
+func TestParseType(t *testing.T) {
+	for _, name := range TypeNames() {
+		typ, err := ParseType(name)
+		if err != nil || typ.String() != name {
+			t.Fatalf("%s: got %v, %v", name, typ, err)
+		}
+	}
+	if typ, err := ParseType("FLOAT32"); err != nil || typ != TypeFloat32 {
+		t.Fatalf("case-insensitive parse failed: %v, %v", typ, err)
+	}
+	if _, err := ParseType("float16"); err == nil {
+		t.Fatal("expected error for float16")
+	}
+}
+
+func float64Regs(f float64) []uint16 {
+	u := math.Float64bits(f)
+	return []uint16{uint16(u >> 48), uint16(u >> 32), uint16(u >> 16), uint16(u)}
+}
@@ -0,0 +1,273 @@
+// internal/metrics/metrics.go
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/stats"
+)
+
+// ContentType is the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Latency buckets exposed for the request histogram. stats.Histogram
+// bucket i holds latencies in [2^i, 2^(i+1)) ns; buckets below
+// minBucket fold into the first bound and those from maxBucket on into
+// +Inf, giving bounds from about 16µs to 34s.
+const (
+	minBucket = 13
+	maxBucket = 35
+)
+
+// Series names one polled value.
+type Series struct {
+	Target   string
+	UnitID   uint8
+	Function uint8
+	Address  uint16
+	Point    string
+}
+
+// Registry holds what a scrape exposes: the last polled values as
+// gauges, and the request counters and latency histogram of the run.
+// It is safe for concurrent use.
+type Registry struct {
+	Counters *stats.Counters
+	Latency  *stats.Histogram
+
+	mu     sync.Mutex
+	values map[Series]float64
+}
+
+// NewRegistry exposes c and h, which the caller keeps updating.
+func NewRegistry(c *stats.Counters, h *stats.Histogram) *Registry {
+	return &Registry{Counters: c, Latency: h, values: map[Series]float64{}}
+}
+
+// Set stores the last value of s.
+func (r *Registry) Set(s Series, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.values[s] = v
+}
+
+// Delete drops s, so a value that could not be read is not scraped as
+// if it were current.
+func (r *Registry) Delete(s Series) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.values, s)
+}
+
+// Record updates the gauges from one poll sample. Each table row with
+// a numeric value sets its series: the scaled value when there is one,
+// otherwise the decoded or raw value. A bitfield is exposed as its
+// register word; text values (-type string) have no gauge and are
+// skipped. Rows with an error, and every series in the span of a
+// sample that failed as a whole, are dropped.
+func (r *Registry) Record(out output.Output) {
+	m := out.Meta
+	if out.Table == nil || len(out.Table.Rows) == 0 {
+		if out.Error != "" && m.Quantity > 0 {
+			r.deleteSpan(m)
+		}
+		return
+	}
+
+	for _, row := range out.Table.Rows {
+		c := row.Cells
+		s := Series{Target: m.Target, UnitID: m.UnitID, Function: m.Function}
+		if fc, ok := number(c["fc"]); ok {
+			s.Function = uint8(fc)
+		}
+		addr, ok := number(c["address"])
+		if !ok {
+			continue
+		}
+		s.Address = uint16(addr)
+		s.Point, _ = c["point"].(string)
+
+		if _, failed := c["error"]; failed {
+			r.Delete(s)
+			continue
+		}
+		if v, ok := rowValue(c); ok {
+			r.Set(s, v)
+		}
+	}
+}
+
+// deleteSpan drops the series read by the failed request m describes.
+func (r *Registry) deleteSpan(m output.Meta) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := int(m.Address) + int(m.Quantity)
+	for s := range r.values {
+		if s.Target == m.Target && s.UnitID == m.UnitID && s.Function == m.Function &&
+			int(s.Address) >= int(m.Address) && int(s.Address) < end {
+			delete(r.values, s)
+		}
+	}
+}
+
+func rowValue(c map[string]any) (float64, bool) {
+	if s, ok := c["scaled"].(format.Engineering); ok {
+		return s.Value, true
+	}
+	if v, ok := c["value"]; ok {
+		return number(v)
+	}
+	return number(c["raw"])
+}
+
+// number converts a numeric, boolean or bitfield cell.
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case format.Bits:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case bool:
+		if n {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// WriteText writes every metric in the Prometheus text format.
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	r.writeValues(bw)
+
+	if r.Counters != nil {
+		req, ok, ex, other, canceled := r.Counters.Snapshot()
+		counter(bw, "rdxbus_requests_total", "Requests that reached the device or failed on the way.", req)
+		counter(bw, "rdxbus_requests_ok_total", "Requests answered with a normal response.", ok)
+		counter(bw, "rdxbus_requests_exceptions_total", "Requests answered with a Modbus exception.", ex)
+		counter(bw, "rdxbus_requests_errors_total", "Requests that failed without a response (timeout, I/O, framing).", other)
+		counter(bw, "rdxbus_requests_canceled_total", "Requests abandoned on shutdown; not part of rdxbus_requests_total.", canceled)
+	}
+
+	if r.Latency != nil {
+		writeHistogram(bw, "rdxbus_request_duration_seconds", "Round trip of requests the device answered.", r.Latency.Snapshot())
+	}
+
+	return bw.Flush()
+}
+
+func (r *Registry) writeValues(w io.Writer) {
+	r.mu.Lock()
+	series := make([]Series, 0, len(r.values))
+	for s := range r.values {
+		series = append(series, s)
+	}
+	values := make([]float64, len(series))
+	sort.Slice(series, func(i, j int) bool { return series[i].less(series[j]) })
+	for i, s := range series {
+		values[i] = r.values[s]
+	}
+	r.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP rdxbus_value Last polled value of a register or map point, scaled when scaling applies.")
+	fmt.Fprintln(w, "# TYPE rdxbus_value gauge")
+	for i, s := range series {
+		fmt.Fprintf(w, "rdxbus_value{target=%s,unit=\"%d\",function=\"%d\",address=\"%d\",point=%s} %s\n",
+			quote(s.Target), s.UnitID, s.Function, s.Address, quote(s.Point), float(values[i]))
+	}
+}
+
+func (s Series) less(o Series) bool {
+	switch {
+	case s.Target != o.Target:
+		return s.Target < o.Target
+	case s.UnitID != o.UnitID:
+		return s.UnitID < o.UnitID
+	case s.Function != o.Function:
+		return s.Function < o.Function
+	case s.Address != o.Address:
+		return s.Address < o.Address
+	}
+	return s.Point < o.Point
+}
+
+func counter(w io.Writer, name, help string, v uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+}
+
+func writeHistogram(w io.Writer, name, help string, h stats.HistSnapshot) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	var cum uint64
+	for i := 0; i < maxBucket; i++ {
+		cum += h.Buckets[i]
+		if i < minBucket {
+			continue
+		}
+		le := float64(uint64(1)<<uint(i+1)) / 1e9
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, float(le), cum)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, float(float64(h.SumNS)/1e9))
+	fmt.Fprintf(w, "%s_count %d\n", name, h.Count)
+}
+
+// float formats v as the text format expects, including NaN and ±Inf.
+func float(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quote quotes a label value.
+func quote(s string) string {
+	return `"` + labelEscaper.Replace(s) + `"`
+}
+
+// ServeHTTP answers a scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteText(w)
+}
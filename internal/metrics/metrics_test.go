@@ -0,0 +1,237 @@
+// internal/metrics/metrics_test.go
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+	"github.com/tamzrod/rdxbus/internal/stats"
+)
+
+// scrape fetches the metrics of s and parses the text format into
+// samples keyed by name and label set, as written, and metric types.
+func scrape(t *testing.T, s *Server) (samples map[string]float64, types map[string]string) {
+	t.Helper()
+	resp, err := http.Get("http://" + s.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != ContentType {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	samples, types = map[string]float64{}, map[string]string{}
+	sc := bufio.NewScanner(strings.NewReader(string(body)))
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "# TYPE "):
+			f := strings.Fields(line)
+			types[f[2]] = f[3]
+			continue
+		case strings.HasPrefix(line, "#"):
+			continue
+		}
+		i := strings.LastIndexByte(line, ' ')
+		if i < 0 {
+			t.Fatalf("malformed sample %q", line)
+		}
+		v, err := strconv.ParseFloat(line[i+1:], 64)
+		if err != nil {
+			t.Fatalf("sample %q: %v", line, err)
+		}
+		samples[line[:i]] = v
+	}
+	return samples, types
+}
+
+func serve(t *testing.T, r *Registry) *Server {
+	t.Helper()
+	s, err := Listen("127.0.0.1:0", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestScrape_ValuesCountersHistogram(t *testing.T) {
+	c, h := &stats.Counters{}, stats.NewHistogram()
+	r := NewRegistry(c, h)
+	s := serve(t, r)
+
+	c.IncRequests()
+	c.IncRequests()
+	c.IncOK()
+	c.IncExceptions()
+	h.Record(300 * time.Microsecond)
+	h.Record(5 * time.Millisecond)
+
+	r.Record(output.Output{
+		Meta: output.Meta{Target: "10.0.0.5:502", UnitID: 1},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"point": "voltage_l1", "fc": 4, "address": 0, "value": float32(2301), "scaled": format.Engineering{Value: 230.1, Unit: "V"}}},
+			{Cells: map[string]any{"point": "relay", "fc": 1, "address": 5, "raw": uint16(1), "value": uint16(1)}},
+			{Cells: map[string]any{"point": `odd "name"`, "fc": 3, "address": 9, "value": int16(-4)}},
+			{Cells: map[string]any{"point": "serial", "fc": 3, "address": 20, "value": "AB1234"}},
+		}},
+	})
+	r.Record(output.Output{
+		Meta: output.Meta{Target: "10.0.0.5:502", UnitID: 1, Function: 3, Address: 100, Quantity: 2},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"address": 100, "raw": uint16(7)}},
+		}},
+	})
+
+	samples, types := scrape(t, s)
+
+	want := map[string]float64{
+		`rdxbus_value{target="10.0.0.5:502",unit="1",function="4",address="0",point="voltage_l1"}`:   230.1,
+		`rdxbus_value{target="10.0.0.5:502",unit="1",function="1",address="5",point="relay"}`:        1,
+		`rdxbus_value{target="10.0.0.5:502",unit="1",function="3",address="9",point="odd \"name\""}`: -4,
+		`rdxbus_value{target="10.0.0.5:502",unit="1",function="3",address="100",point=""}`:           7,
+		`rdxbus_requests_total`:                             2,
+		`rdxbus_requests_ok_total`:                          1,
+		`rdxbus_requests_exceptions_total`:                  1,
+		`rdxbus_requests_errors_total`:                      0,
+		`rdxbus_request_duration_seconds_count`:             2,
+		`rdxbus_request_duration_seconds_bucket{le="+Inf"}`: 2,
+	}
+	for k, v := range want {
+		got, ok := samples[k]
+		if !ok {
+			t.Fatalf("missing %s", k)
+		}
+		if got != v {
+			t.Fatalf("%s = %v, want %v", k, got, v)
+		}
+	}
+	for k := range samples {
+		if strings.Contains(k, `point="serial"`) {
+			t.Fatalf("non-numeric value exposed: %s", k)
+		}
+	}
+
+	if got := samples["rdxbus_request_duration_seconds_sum"]; got < 0.0053 || got > 0.00531 {
+		t.Fatalf("sum = %v", got)
+	}
+	// 300µs falls in [262144ns, 524288ns), 5ms in [4194304ns, 8388608ns).
+	if got := samples[`rdxbus_request_duration_seconds_bucket{le="0.000524288"}`]; got != 1 {
+		t.Fatalf("bucket le=0.000524288 = %v, want 1", got)
+	}
+	if got := samples[`rdxbus_request_duration_seconds_bucket{le="0.008388608"}`]; got != 2 {
+		t.Fatalf("bucket le=0.008388608 = %v, want 2", got)
+	}
+
+	for name, typ := range map[string]string{
+		"rdxbus_value":                    "gauge",
+		"rdxbus_requests_total":           "counter",
+		"rdxbus_request_duration_seconds": "histogram",
+	} {
+		if types[name] != typ {
+			t.Fatalf("type of %s = %q, want %q", name, types[name], typ)
+		}
+	}
+}
+
+func TestHistogramBucketsAreCumulative(t *testing.T) {
+	h := stats.NewHistogram()
+	for _, d := range []time.Duration{time.Nanosecond, 20 * time.Microsecond, time.Millisecond, time.Minute} {
+		h.Record(d)
+	}
+	samples, _ := scrape(t, serve(t, NewRegistry(nil, h)))
+
+	var last float64
+	for i := minBucket; i < maxBucket; i++ {
+		key := `rdxbus_request_duration_seconds_bucket{le="` + float(float64(uint64(1)<<uint(i+1))/1e9) + `"}`
+		v, ok := samples[key]
+		if !ok {
+			t.Fatalf("missing %s", key)
+		}
+		if v < last {
+			t.Fatalf("%s = %v below previous bucket %v", key, v, last)
+		}
+		last = v
+	}
+	// 1ns folds into the first bucket; a minute only counts in +Inf.
+	if first := samples[`rdxbus_request_duration_seconds_bucket{le="1.6384e-05"}`]; first != 1 {
+		t.Fatalf("first bucket = %v, want 1", first)
+	}
+	if last != 3 || samples[`rdxbus_request_duration_seconds_bucket{le="+Inf"}`] != 4 {
+		t.Fatalf("last bucket %v, +Inf %v", last, samples[`rdxbus_request_duration_seconds_bucket{le="+Inf"}`])
+	}
+	if _, ok := samples["rdxbus_requests_total"]; ok {
+		t.Fatal("counters exposed without stats.Counters")
+	}
+}
+
+func TestRecord_BitfieldsAndText(t *testing.T) {
+	r := NewRegistry(nil, nil)
+	r.Record(output.Output{
+		Meta: output.Meta{Target: "dev", UnitID: 1, Function: 3, Address: 0, Quantity: 3},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"address": 0, "raw": "0000 0000 0000 0101", "value": format.Bits(5), "running": 1}},
+			{Cells: map[string]any{"address": 1, "raw": "0x1234", "value": uint16(1234)}}, // bcd16
+			{Cells: map[string]any{"address": 2, "raw": "0x4142", "value": "AB"}},
+		}},
+	})
+
+	samples, _ := scrape(t, serve(t, r))
+	series := func(addr int) string {
+		return `rdxbus_value{target="dev",unit="1",function="3",address="` + strconv.Itoa(addr) + `",point=""}`
+	}
+	if v, ok := samples[series(0)]; !ok || v != 5 {
+		t.Fatalf("bitfield = %v, %v; want its word 5", v, ok)
+	}
+	if v, ok := samples[series(1)]; !ok || v != 1234 {
+		t.Fatalf("bcd = %v, %v; want 1234", v, ok)
+	}
+	if _, ok := samples[series(2)]; ok {
+		t.Fatal("text value exposed as a gauge")
+	}
+}
+
+func TestRecord_DropsFailedValues(t *testing.T) {
+	r := NewRegistry(nil, nil)
+	meta := output.Meta{Target: "dev", UnitID: 1, Function: 3, Address: 10, Quantity: 2}
+	r.Record(output.Output{
+		Meta: meta,
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"address": 10, "raw": uint16(1)}},
+			{Cells: map[string]any{"address": 11, "raw": uint16(2)}},
+		}},
+	})
+	r.Record(output.Output{
+		Meta: output.Meta{Target: "dev", UnitID: 1},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"point": "p", "fc": 4, "address": 0, "value": 3}},
+		}},
+	})
+
+	r.Record(output.Output{Meta: meta, Error: "i/o timeout"})
+	r.Record(output.Output{
+		Meta: output.Meta{Target: "dev", UnitID: 1},
+		Table: &output.Table{Rows: []output.Row{
+			{Cells: map[string]any{"point": "p", "fc": 4, "address": 0, "error": "modbus exception fc=4 code=2"}},
+		}},
+	})
+
+	samples, _ := scrape(t, serve(t, r))
+	for k := range samples {
+		if strings.HasPrefix(k, "rdxbus_value") {
+			t.Fatalf("stale value still exposed: %s", k)
+		}
+	}
+}
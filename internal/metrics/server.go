@@ -0,0 +1,39 @@
+// internal/metrics/server.go
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// Server exposes a Registry at /metrics over plain HTTP.
+type Server struct {
+	srv *http.Server
+	ln  net.Listener
+}
+
+// Listen starts serving r on addr, e.g. ":9102". Port 0 picks a free
+// port; Addr reports it.
+func Listen(addr string, r *Registry) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+
+	s := &Server{
+		srv: &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second},
+		ln:  ln,
+	}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// Addr is the address the server listens on.
+func (s *Server) Addr() string { return s.ln.Addr().String() }
+
+// Close stops the server.
+func (s *Server) Close() error { return s.srv.Close() }
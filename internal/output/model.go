@@ -8,6 +8,9 @@ type Output struct {
 	Table   *Table
 	Message string
 	Error   string
+
+	// Exception is the Modbus exception code behind Error, or 0.
+	Exception uint8
 }
 
 type Meta struct {
@@ -19,6 +22,11 @@ type Meta struct {
 	Quantity  uint16
 	Latency   time.Duration
 	Timestamp time.Time
+
+	// Modbus/TCP Security handshake, when the target was reached over TLS.
+	TLS         string // protocol version and cipher suite
+	PeerSubject string // server certificate subject
+	PeerRole    string // server certificate role extension
 }
 
 type Table struct {
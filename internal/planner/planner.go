@@ -0,0 +1,305 @@
+// internal/planner/planner.go
+package planner
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/engine"
+)
+
+// Point is a span of one table to read: Length registers (or bits)
+// from Address, with read function code Function.
+type Point struct {
+	Function uint8
+	Address  uint16
+	Length   uint16
+}
+
+func (p Point) end() int { return int(p.Address) + int(p.Length) }
+
+// Range is the address span [Start, End) of one table that no batch of
+// several points may cover. An empty range (Start == End) is a cut: no
+// batch of several points may straddle Start.
+type Range struct {
+	Function uint8
+	Start    int
+	End      int
+}
+
+// blocks reports whether a read of [start, end) runs into r.
+func (r Range) blocks(fc uint8, start, end int) bool {
+	if r.Function != fc {
+		return false
+	}
+	if r.Start == r.End {
+		return start < r.Start && r.Start < end
+	}
+	return start < r.End && r.Start < end
+}
+
+// Limits bound the reads a Planner produces.
+type Limits struct {
+	// MaxQuantity caps the read quantity per function code. Missing
+	// entries (or 0) use the protocol limit: 2000 bits or 125
+	// registers.
+	MaxQuantity map[uint8]uint16
+
+	// MaxGap is the largest run of unwanted addresses read to join
+	// two points into one request.
+	MaxGap uint16
+
+	// Forbidden ranges are known holes of the device map.
+	Forbidden []Range
+}
+
+// MaxQuantity is the protocol limit of a read with fc: 2000 coils or
+// discrete inputs, or 125 registers.
+func MaxQuantity(fc uint8) uint16 {
+	if fc == 1 || fc == 2 {
+		return 2000
+	}
+	return 125
+}
+
+func (l Limits) maxQuantity(fc uint8) uint16 {
+	max := MaxQuantity(fc)
+	if n := l.MaxQuantity[fc]; n > 0 && n < max {
+		return n
+	}
+	return max
+}
+
+// Batch is one planned read covering one or more points.
+type Batch struct {
+	Function uint8
+	Address  uint16
+	Quantity uint16
+
+	// Members are the indexes of the covered points in the slice
+	// given to Plan, in address order.
+	Members []int
+
+	spans []Point
+}
+
+func (b Batch) end() int { return int(b.Address) + int(b.Quantity) }
+
+// Request is the engine read for b.
+func (b Batch) Request(unitID uint8, timeout time.Duration) engine.Request {
+	return engine.Request{
+		UnitID:       unitID,
+		FunctionCode: b.Function,
+		Address:      b.Address,
+		Quantity:     b.Quantity,
+		Timeout:      timeout,
+	}
+}
+
+// Planner turns a set of points into as few reads as Limits allow.
+//
+// With Adaptive, Execute splits a batch the device rejects and the
+// Planner remembers where: later plans keep the two sides apart. The
+// zero value plans touching points together within the protocol
+// limits. A Planner is safe for concurrent use.
+type Planner struct {
+	Limits   Limits
+	Adaptive bool
+
+	mu      sync.Mutex
+	learned []Range
+}
+
+// Plan groups points per function code, left to right: a point joins
+// the current batch when the bridged gap is at most MaxGap, the batch
+// stays within MaxQuantity and it would not cover a forbidden or
+// learned range. Packing sorted points greedily this way gives the
+// fewest batches. Batches are ordered by function code, then address.
+func (p *Planner) Plan(points []Point) []Batch {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := points[order[i]], points[order[j]]
+		if a.Function != b.Function {
+			return a.Function < b.Function
+		}
+		return a.Address < b.Address
+	})
+
+	blocked := p.ranges()
+
+	var batches []Batch
+	for _, i := range order {
+		pt := points[i]
+		if n := len(batches); n > 0 && p.fits(&batches[n-1], pt, blocked) {
+			b := &batches[n-1]
+			if e := pt.end(); e > b.end() {
+				b.Quantity = uint16(e - int(b.Address))
+			}
+			b.Members = append(b.Members, i)
+			b.spans = append(b.spans, pt)
+			continue
+		}
+		batches = append(batches, Batch{
+			Function: pt.Function,
+			Address:  pt.Address,
+			Quantity: pt.Length,
+			Members:  []int{i},
+			spans:    []Point{pt},
+		})
+	}
+	return batches
+}
+
+// fits reports whether pt may join b.
+func (p *Planner) fits(b *Batch, pt Point, blocked []Range) bool {
+	if b.Function != pt.Function {
+		return false
+	}
+	if gap := int(pt.Address) - b.end(); gap > int(p.Limits.MaxGap) {
+		return false
+	}
+	end := b.end()
+	if e := pt.end(); e > end {
+		end = e
+	}
+	if end-int(b.Address) > int(p.Limits.maxQuantity(pt.Function)) {
+		return false
+	}
+	for _, r := range blocked {
+		if r.blocks(pt.Function, int(b.Address), end) {
+			return false
+		}
+	}
+	return true
+}
+
+// ranges returns the forbidden and learned ranges.
+func (p *Planner) ranges() []Range {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append(append([]Range(nil), p.Limits.Forbidden...), p.learned...)
+}
+
+// Learned returns the ranges learned from rejected batches.
+func (p *Planner) Learned() []Range {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Range(nil), p.learned...)
+}
+
+// Split handles a batch the device answered with exception code. Only
+// exceptions 02 (illegal data address) and 03 (illegal data value,
+// often a quantity the device will not serve) are acted on.
+//
+// A batch of several points is split in two at the widest gap between
+// its points (the likeliest hole), nearest the middle on ties. The gap
+// is remembered as a forbidden range, or as a cut when the points
+// touch. A single point rejected with 02 is remembered as forbidden so
+// later plans read it alone. Split returns nil when the batch cannot
+// be split; its error then stands.
+func (p *Planner) Split(b Batch, code uint8) []Batch {
+	if code != 2 && code != 3 {
+		return nil
+	}
+	if len(b.spans) < 2 {
+		if code == 2 && len(b.spans) == 1 {
+			p.learn(Range{Function: b.Function, Start: int(b.Address), End: b.end()})
+		}
+		return nil
+	}
+
+	// The split goes before spans[at]: left covers spans[:at].
+	at, best := 0, -1
+	leftEnd := 0
+	for i := 1; i < len(b.spans); i++ {
+		if e := b.spans[i-1].end(); e > leftEnd {
+			leftEnd = e
+		}
+		gap := int(b.spans[i].Address) - leftEnd
+		mid := len(b.spans) / 2
+		if gap > best || gap == best && abs(i-mid) < abs(at-mid) {
+			at, best = i, gap
+		}
+	}
+
+	left := regroup(b, 0, at)
+	right := regroup(b, at, len(b.spans))
+	if best > 0 {
+		p.learn(Range{Function: b.Function, Start: left.end(), End: int(right.Address)})
+	} else {
+		p.learn(Range{Function: b.Function, Start: int(right.Address), End: int(right.Address)})
+	}
+	return []Batch{left, right}
+}
+
+// regroup builds the batch of b's points [from, to).
+func regroup(b Batch, from, to int) Batch {
+	out := Batch{
+		Function: b.Function,
+		Address:  b.spans[from].Address,
+		Members:  append([]int(nil), b.Members[from:to]...),
+		spans:    append([]Point(nil), b.spans[from:to]...),
+	}
+	end := 0
+	for _, s := range out.spans {
+		if e := s.end(); e > end {
+			end = e
+		}
+	}
+	out.Quantity = uint16(end - int(out.Address))
+	return out
+}
+
+func (p *Planner) learn(r Range) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, l := range p.learned {
+		if l == r {
+			return
+		}
+	}
+	p.learned = append(p.learned, r)
+}
+
+// Outcome is the result of one executed batch.
+type Outcome struct {
+	Batch  Batch
+	Result engine.Result
+}
+
+// Execute reads every batch through eng, in order. With Adaptive, a
+// batch rejected with an exception that Split acts on is replaced by
+// its two halves, which are read next; Split remembers the division
+// for later plans.
+func (p *Planner) Execute(ctx context.Context, eng engine.Engine, batches []Batch, unitID uint8, timeout time.Duration) []Outcome {
+	queue := append([]Batch(nil), batches...)
+	out := make([]Outcome, 0, len(queue))
+
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+
+		res := eng.Execute(ctx, b.Request(unitID, timeout))
+		if me, ok := client.IsModbusException(res.Err); ok && p.Adaptive {
+			if parts := p.Split(b, me.Code); parts != nil {
+				queue = append(parts, queue...)
+				continue
+			}
+		}
+		out = append(out, Outcome{Batch: b, Result: res})
+	}
+	return out
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,139 @@
+// internal/planner/planner_test.go
+package planner
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/client"
+	"github.com/tamzrod/rdxbus/internal/engine"
+)
+
+// holeEngine rejects reads that touch hole with exception 02 and
+// records every request it sees.
+type holeEngine struct {
+	hole []int
+	seen []engine.Request
+}
+
+func (e *holeEngine) Execute(_ context.Context, req engine.Request) engine.Result {
+	e.seen = append(e.seen, req)
+	for _, a := range e.hole {
+		if a >= int(req.Address) && a < int(req.Address)+int(req.Quantity) {
+			return engine.Result{Err: &client.ModbusExceptionError{Function: req.FunctionCode, Code: 2}}
+		}
+	}
+	return engine.Result{}
+}
+
+type span struct{ fc, addr, qty int }
+
+func spansOf(batches []Batch) []span {
+	out := make([]span, len(batches))
+	for i, b := range batches {
+		out[i] = span{int(b.Function), int(b.Address), int(b.Quantity)}
+	}
+	return out
+}
+
+func TestPlan_BridgesGaps(t *testing.T) {
+	points := []Point{
+		{3, 10, 1},
+		{3, 0, 2},
+		{4, 0, 1},
+		{3, 3, 1},
+	}
+
+	p := &Planner{}
+	if got, want := spansOf(p.Plan(points)), []span{{3, 0, 2}, {3, 3, 1}, {3, 10, 1}, {4, 0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("no gap: got %v, want %v", got, want)
+	}
+
+	p = &Planner{Limits: Limits{MaxGap: 1}}
+	batches := p.Plan(points)
+	if got, want := spansOf(batches), []span{{3, 0, 4}, {3, 10, 1}, {4, 0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("gap 1: got %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(batches[0].Members, []int{1, 3}) {
+		t.Fatalf("members: got %v", batches[0].Members)
+	}
+}
+
+func TestPlan_Limits(t *testing.T) {
+	points := []Point{{3, 0, 2}, {3, 2, 2}, {3, 4, 2}, {3, 8, 1}}
+
+	p := &Planner{Limits: Limits{MaxQuantity: map[uint8]uint16{3: 4}, MaxGap: 10}}
+	if got, want := spansOf(p.Plan(points)), []span{{3, 0, 4}, {3, 4, 2}}; !reflect.DeepEqual(got[:2], want) {
+		t.Fatalf("max quantity: got %v", got)
+	}
+
+	p = &Planner{Limits: Limits{MaxGap: 10, Forbidden: []Range{{Function: 3, Start: 6, End: 8}}}}
+	if got, want := spansOf(p.Plan(points)), []span{{3, 0, 6}, {3, 8, 1}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("forbidden: got %v, want %v", got, want)
+	}
+
+	// A forbidden range in another table does not matter.
+	p = &Planner{Limits: Limits{MaxGap: 10, Forbidden: []Range{{Function: 4, Start: 6, End: 8}}}}
+	if got := p.Plan(points); len(got) != 1 || got[0].Quantity != 9 {
+		t.Fatalf("other table: got %v", spansOf(got))
+	}
+}
+
+func TestExecute_AdaptiveSplitIsRemembered(t *testing.T) {
+	points := []Point{{3, 0, 4}, {3, 7, 3}}
+	eng := &holeEngine{hole: []int{5}}
+	p := &Planner{Limits: Limits{MaxGap: 3}, Adaptive: true}
+
+	out := p.Execute(context.Background(), eng, p.Plan(points), 1, 0)
+	if len(out) != 2 || out[0].Result.Err != nil || out[1].Result.Err != nil {
+		t.Fatalf("got %+v", out)
+	}
+	if len(eng.seen) != 3 || eng.seen[0].Quantity != 10 {
+		t.Fatalf("requests: %+v", eng.seen)
+	}
+	if got, want := p.Learned(), []Range{{Function: 3, Start: 4, End: 7}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("learned: got %v, want %v", got, want)
+	}
+
+	// Next time the plan already keeps the two sides apart.
+	eng.seen = nil
+	out = p.Execute(context.Background(), eng, p.Plan(points), 1, 0)
+	if len(out) != 2 || len(eng.seen) != 2 {
+		t.Fatalf("second run: %d outcomes, %d requests", len(out), len(eng.seen))
+	}
+}
+
+func TestSplit_TouchingPoints(t *testing.T) {
+	// The device refuses reads across 10, although both sides are valid.
+	p := &Planner{Adaptive: true}
+	b := p.Plan([]Point{{3, 8, 2}, {3, 10, 2}})[0]
+
+	parts := p.Split(b, 2)
+	if got, want := spansOf(parts), []span{{3, 8, 2}, {3, 10, 2}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v", got)
+	}
+	if got := p.Plan([]Point{{3, 8, 2}, {3, 10, 2}}); len(got) != 2 {
+		t.Fatalf("cut not remembered: %v", spansOf(got))
+	}
+}
+
+func TestExecute_SinglePointErrorStands(t *testing.T) {
+	eng := &holeEngine{hole: []int{5}}
+
+	p := &Planner{Adaptive: true}
+	out := p.Execute(context.Background(), eng, p.Plan([]Point{{3, 5, 1}}), 1, 0)
+	if me, ok := client.IsModbusException(out[0].Result.Err); !ok || me.Code != 2 {
+		t.Fatalf("got %v", out[0].Result.Err)
+	}
+	if got := p.Learned(); len(got) != 1 || got[0].Start != 5 || got[0].End != 6 {
+		t.Fatalf("learned: %v", got)
+	}
+
+	// Without Adaptive the exception of a batch is returned as is.
+	p = &Planner{Limits: Limits{MaxGap: 5}}
+	out = p.Execute(context.Background(), eng, p.Plan([]Point{{3, 0, 1}, {3, 6, 1}}), 1, 0)
+	if len(out) != 1 || out[0].Result.Err == nil || len(p.Learned()) != 0 {
+		t.Fatalf("got %+v", out)
+	}
+}
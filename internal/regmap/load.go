@@ -0,0 +1,102 @@
+// internal/regmap/load.go
+package regmap
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Load reads a register map, choosing the format by extension:
+// .yaml or .yml, .json, or .csv.
+func Load(path string) (*Map, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *Map
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		m, err = ParseYAML(b)
+	case ".json":
+		m, err = ParseJSON(b)
+	case ".csv":
+		m, err = ParseCSV(bytes.NewReader(b))
+	default:
+		return nil, fmt.Errorf("%s: unknown map format %q (want .yaml, .json or .csv)", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// ParseJSON reads {"points": [...]} or a bare array of points. Field
+// values may be strings or numbers.
+func ParseJSON(b []byte) (*Map, error) {
+	var doc struct {
+		Points []map[string]any `json:"points"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var err error
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '[' {
+		err = dec.Decode(&doc.Points)
+	} else {
+		err = dec.Decode(&doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]map[string]string, 0, len(doc.Points))
+	for _, raw := range doc.Points {
+		fields := make(map[string]string, len(raw))
+		for k, v := range raw {
+			switch v.(type) {
+			case string, json.Number, bool:
+				fields[fieldKey(k)] = fmt.Sprint(v)
+			default:
+				return nil, fmt.Errorf("field %q: want a string or number", k)
+			}
+		}
+		entries = append(entries, fields)
+	}
+	return newMap(entries)
+}
+
+// ParseCSV reads a header row of field names, then one point per row.
+// Empty cells are omitted; lines starting with # are comments.
+func ParseCSV(r io.Reader) (*Map, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("map has no points")
+	}
+
+	header := records[0]
+	entries := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		fields := map[string]string{}
+		for i, v := range rec {
+			if v = strings.TrimSpace(v); v != "" {
+				fields[fieldKey(header[i])] = v
+			}
+		}
+		entries = append(entries, fields)
+	}
+	return newMap(entries)
+}
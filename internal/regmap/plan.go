@@ -0,0 +1,103 @@
+// internal/regmap/plan.go
+package regmap
+
+import (
+	"fmt"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/planner"
+)
+
+// Batch is one planned read and the points it covers.
+type Batch struct {
+	planner.Batch
+	Points []Point
+}
+
+// Spans returns the planner points for points, index for index.
+func Spans(points []Point) []planner.Point {
+	out := make([]planner.Point, len(points))
+	for i, p := range points {
+		out[i] = planner.Point{Function: p.Function, Address: p.Address, Length: p.Length}
+	}
+	return out
+}
+
+// Group attaches to a batch planned from Spans(points) the points it
+// covers.
+func Group(b planner.Batch, points []Point) Batch {
+	out := Batch{Batch: b, Points: make([]Point, len(b.Members))}
+	for i, m := range b.Members {
+		out.Points[i] = points[m]
+	}
+	return out
+}
+
+// Plan groups points into reads with pl; a zero Planner reads points
+// of the same table that touch or overlap together, within the
+// protocol limits.
+func Plan(pl *planner.Planner, points []Point) []Batch {
+	planned := pl.Plan(Spans(points))
+	out := make([]Batch, len(planned))
+	for i, b := range planned {
+		out[i] = Group(b, points)
+	}
+	return out
+}
+
+// Reading is the value of one point.
+type Reading struct {
+	Point Point
+	Raw   []uint16
+
+	// Value is the decoded value; Scaled is set when the point has a
+	// scale, offset or unit.
+	Value  any
+	Scaled *format.Engineering
+
+	// Err is set when the point's registers could not be decoded or
+	// scaled, e.g. an invalid BCD digit; other points are unaffected.
+	Err error
+}
+
+// Split cuts the values read for b (as returned by
+// format.DecodeReadValues) into one reading per point. Only a read of
+// the wrong length fails the whole batch.
+func (b Batch) Split(values []uint16) ([]Reading, error) {
+	if len(values) != int(b.Quantity) {
+		return nil, fmt.Errorf("got %d values for a read of %d", len(values), b.Quantity)
+	}
+
+	out := make([]Reading, 0, len(b.Points))
+	for _, p := range b.Points {
+		off := int(p.Address) - int(b.Address)
+		r, err := read(p, values[off:off+int(p.Length)])
+		r.Err = err
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// read decodes one point from its registers (or bit).
+func read(p Point, regs []uint16) (Reading, error) {
+	r := Reading{Point: p, Raw: regs}
+	if p.Function == 1 || p.Function == 2 {
+		r.Value = regs[0]
+		return r, nil
+	}
+
+	decoded, err := format.DecodeTyped(p.Address, regs, p.Type, p.Order)
+	if err != nil {
+		return r, err
+	}
+	r.Value = decoded[0].Value
+
+	if !p.Scaling.IsZero() {
+		eng, err := p.Scaling.Apply(r.Value, 0)
+		if err != nil {
+			return r, err
+		}
+		r.Scaled = &eng
+	}
+	return r, nil
+}
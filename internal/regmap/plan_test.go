@@ -0,0 +1,103 @@
+// internal/regmap/plan_test.go
+package regmap
+
+import (
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/planner"
+)
+
+func reg(name string, fc uint8, addr, length uint16) Point {
+	return Point{Name: name, Function: fc, Address: addr, Length: length, Type: format.TypeUint16, Access: AccessRead}
+}
+
+func TestPlan_GroupsContiguousPoints(t *testing.T) {
+	batches := Plan(&planner.Planner{}, []Point{
+		reg("c", 3, 12, 1),
+		reg("a", 3, 10, 2),
+		reg("in", 4, 10, 1),
+		reg("far", 3, 20, 1),
+		reg("coil", 1, 0, 1),
+	})
+
+	want := []struct{ fc, addr, qty uint16 }{
+		{1, 0, 1},
+		{3, 10, 3},
+		{3, 20, 1},
+		{4, 10, 1},
+	}
+	if len(batches) != len(want) {
+		t.Fatalf("got %d batches: %+v", len(batches), batches)
+	}
+	for i, w := range want {
+		b := batches[i]
+		if uint16(b.Function) != w.fc || b.Address != w.addr || b.Quantity != w.qty {
+			t.Fatalf("batch %d: got fc=%d addr=%d qty=%d", i, b.Function, b.Address, b.Quantity)
+		}
+	}
+	if len(batches[1].Points) != 2 || batches[1].Points[0].Name != "a" {
+		t.Fatalf("unexpected points %+v", batches[1].Points)
+	}
+}
+
+func TestPlan_RespectsMaxQuantity(t *testing.T) {
+	var points []Point
+	for a := uint16(0); a < 130; a += 2 {
+		points = append(points, reg("p", 3, a, 2))
+	}
+	batches := Plan(&planner.Planner{}, points)
+	if len(batches) != 2 || batches[0].Quantity != 124 || batches[1].Address != 124 || batches[1].Quantity != 6 {
+		t.Fatalf("got %+v", batches)
+	}
+}
+
+func TestPlan_BridgesGapsWithPlanner(t *testing.T) {
+	points := []Point{reg("a", 3, 0, 2), reg("b", 3, 5, 1)}
+	batches := Plan(&planner.Planner{Limits: planner.Limits{MaxGap: 3}}, points)
+	if len(batches) != 1 || batches[0].Quantity != 6 || batches[0].Points[1].Name != "b" {
+		t.Fatalf("got %+v", batches)
+	}
+}
+
+func TestBatch_Split(t *testing.T) {
+	volts := Point{Name: "volts", Function: 4, Address: 0, Length: 2, Type: format.TypeFloat32, Order: format.OrderCDAB, Scaling: format.Scaling{Unit: "V"}}
+	count := Point{Name: "count", Function: 4, Address: 2, Length: 1, Type: format.TypeUint16, Scaling: format.Scaling{Scale: 0.1, Unit: "A"}}
+	raw := Point{Name: "raw", Function: 4, Address: 3, Length: 1, Type: format.TypeInt16}
+
+	b := Plan(&planner.Planner{}, []Point{volts, count, raw})[0]
+	got, err := b.Split([]uint16{0x199A, 0x4366, 52, 0xFFFF})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Value != float32(230.1) || got[0].Scaled == nil || got[0].Scaled.String() != "230.1 V" {
+		t.Fatalf("volts: %+v", got[0])
+	}
+	if got[1].Scaled.String() != "5.2 A" {
+		t.Fatalf("count: %+v", got[1])
+	}
+	if got[2].Value != int16(-1) || got[2].Scaled != nil {
+		t.Fatalf("raw: %+v", got[2])
+	}
+
+	if _, err := b.Split([]uint16{1}); err == nil {
+		t.Fatal("expected error for short read")
+	}
+}
+
+func TestBatch_SplitKeepsErrorsPerPoint(t *testing.T) {
+	bcd := Point{Name: "bcd", Function: 3, Address: 0, Length: 1, Type: format.TypeBCD16}
+	word := Point{Name: "word", Function: 3, Address: 1, Length: 1, Type: format.TypeUint16}
+
+	b := Plan(&planner.Planner{}, []Point{bcd, word})[0]
+	got, err := b.Split([]uint16{0x12AF, 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].Err == nil {
+		t.Fatalf("bcd: expected a decode error, got %+v", got[0])
+	}
+	if got[1].Err != nil || got[1].Value != uint16(7) {
+		t.Fatalf("word: %+v", got[1])
+	}
+}
@@ -0,0 +1,277 @@
+// internal/regmap/regmap.go
+package regmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/planner"
+)
+
+// Access says whether a point may be read, written, or both.
+type Access uint8
+
+const (
+	AccessRead Access = 1 << iota
+	AccessWrite
+
+	AccessReadWrite = AccessRead | AccessWrite
+)
+
+// CanRead reports whether the point may be read.
+func (a Access) CanRead() bool { return a&AccessRead != 0 }
+
+// CanWrite reports whether the point may be written.
+func (a Access) CanWrite() bool { return a&AccessWrite != 0 }
+
+func (a Access) String() string {
+	switch a {
+	case AccessRead:
+		return "r"
+	case AccessWrite:
+		return "w"
+	case AccessReadWrite:
+		return "rw"
+	}
+	return fmt.Sprintf("Access(%d)", uint8(a))
+}
+
+// Point is one named value of a device: where it lives and how its
+// registers are decoded.
+type Point struct {
+	Name string
+
+	// Function is the read function code of the point's table:
+	// 1 coils, 2 discrete inputs, 3 holding or 4 input registers.
+	Function uint8
+	Address  uint16
+
+	// Length is the number of registers (or bits) the point spans.
+	Length uint16
+
+	Type    format.Type
+	Order   format.Order
+	Scaling format.Scaling
+	Access  Access
+}
+
+// End is the address just past the point.
+func (p Point) End() int { return int(p.Address) + int(p.Length) }
+
+// Map is a device register map: its points in file order.
+type Map struct {
+	Points []Point
+}
+
+// Lookup returns the point called name.
+func (m *Map) Lookup(name string) (Point, bool) {
+	for _, p := range m.Points {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Point{}, false
+}
+
+// Select returns the named points, or every readable point when no
+// names are given. Naming an unknown or write-only point is an error.
+func (m *Map) Select(names ...string) ([]Point, error) {
+	if len(names) == 0 {
+		var out []Point
+		for _, p := range m.Points {
+			if p.Access.CanRead() {
+				out = append(out, p)
+			}
+		}
+		if len(out) == 0 {
+			return nil, fmt.Errorf("map has no readable points")
+		}
+		return out, nil
+	}
+
+	out := make([]Point, 0, len(names))
+	for _, name := range names {
+		p, ok := m.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown point %q", name)
+		}
+		if !p.Access.CanRead() {
+			return nil, fmt.Errorf("point %q is write-only", name)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// newMap builds a map from one field set per point, as read from any
+// of the file formats. Field names are case-insensitive; "-" and "_"
+// are interchangeable.
+func newMap(entries []map[string]string) (*Map, error) {
+	m := &Map{}
+	seen := map[string]bool{}
+	for i, fields := range entries {
+		p, err := pointFromFields(fields)
+		if err != nil {
+			if name := fields["name"]; name != "" {
+				return nil, fmt.Errorf("point %q: %w", name, err)
+			}
+			return nil, fmt.Errorf("point %d: %w", i+1, err)
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate point %q", p.Name)
+		}
+		seen[p.Name] = true
+		m.Points = append(m.Points, p)
+	}
+	if len(m.Points) == 0 {
+		return nil, fmt.Errorf("map has no points")
+	}
+	return m, nil
+}
+
+// fieldKey normalises a field name: "Byte-Order" becomes "byte_order".
+func fieldKey(k string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(k)), "-", "_")
+}
+
+// pointFromFields validates one point. Known fields: name, table (or
+// fc), address, type, byte_order, length, scale, offset, unit, access.
+func pointFromFields(fields map[string]string) (Point, error) {
+	p := Point{Type: format.TypeUint16, Order: format.OrderABCD}
+	var (
+		access string
+		err    error
+	)
+
+	for k, v := range fields {
+		switch k {
+		case "name":
+			p.Name = v
+		case "table", "fc":
+			fc, err := parseTable(v)
+			if err != nil {
+				return p, err
+			}
+			if p.Function != 0 && p.Function != fc {
+				return p, fmt.Errorf("table and fc disagree")
+			}
+			p.Function = fc
+		case "address":
+			n, err := strconv.ParseUint(v, 0, 16)
+			if err != nil {
+				return p, fmt.Errorf("invalid address %q", v)
+			}
+			p.Address = uint16(n)
+		case "type":
+			if p.Type, err = format.ParseType(v); err != nil {
+				return p, err
+			}
+		case "byte_order", "order":
+			if p.Order, err = format.ParseOrder(v); err != nil {
+				return p, err
+			}
+		case "length":
+			n, err := strconv.ParseUint(v, 0, 16)
+			if err != nil || n == 0 {
+				return p, fmt.Errorf("invalid length %q", v)
+			}
+			p.Length = uint16(n)
+		case "scale":
+			if p.Scaling.Scale, err = strconv.ParseFloat(v, 64); err != nil || p.Scaling.Scale == 0 {
+				return p, fmt.Errorf("invalid scale %q", v)
+			}
+		case "offset":
+			if p.Scaling.Offset, err = strconv.ParseFloat(v, 64); err != nil {
+				return p, fmt.Errorf("invalid offset %q", v)
+			}
+		case "unit":
+			p.Scaling.Unit = v
+		case "access":
+			access = v
+		default:
+			return p, fmt.Errorf("unknown field %q", k)
+		}
+	}
+
+	if p.Name == "" {
+		return p, fmt.Errorf("name required")
+	}
+	if p.Function == 0 {
+		return p, fmt.Errorf("table required")
+	}
+	if p.Access, err = parseAccess(access, p.Function); err != nil {
+		return p, err
+	}
+
+	switch {
+	case p.Function == 1 || p.Function == 2:
+		if p.Type != format.TypeUint16 || !p.Scaling.IsZero() {
+			return p, fmt.Errorf("bit tables take no type or scaling")
+		}
+		p.Length = 1
+	case p.Type == format.TypeString:
+		if p.Length == 0 || p.Length > planner.MaxQuantity(p.Function) {
+			return p, fmt.Errorf("type string needs a length of 1-%d registers", planner.MaxQuantity(p.Function))
+		}
+		if p.Order != format.OrderABCD && p.Order != format.OrderBADC {
+			return p, fmt.Errorf("type string takes byte order ABCD or BADC")
+		}
+	default:
+		n := uint16(p.Type.Registers())
+		if p.Length != 0 && p.Length != n {
+			return p, fmt.Errorf("type %s spans %d registers, not %d", p.Type, n, p.Length)
+		}
+		p.Length = n
+		if !p.Scaling.IsZero() && !p.Type.Numeric() && p.Type != format.TypeBCD16 && p.Type != format.TypeBCD32 {
+			return p, fmt.Errorf("type %s cannot be scaled", p.Type)
+		}
+	}
+
+	if p.End() > 0x10000 {
+		return p, fmt.Errorf("point runs past address 65535")
+	}
+	return p, nil
+}
+
+// parseTable accepts a table name or its read function code.
+func parseTable(s string) (uint8, error) {
+	switch strings.ToLower(s) {
+	case "1", "coil", "coils":
+		return 1, nil
+	case "2", "discrete", "discrete_input", "discrete_inputs":
+		return 2, nil
+	case "3", "holding", "holding_register", "holding_registers":
+		return 3, nil
+	case "4", "input", "input_register", "input_registers":
+		return 4, nil
+	}
+	return 0, fmt.Errorf("unknown table %q (want coil, discrete, holding or input)", s)
+}
+
+// parseAccess parses r, w or rw. Coils and holding registers default
+// to rw, discrete and input registers to r (and cannot be written).
+func parseAccess(s string, fc uint8) (Access, error) {
+	writable := fc == 1 || fc == 3
+	var a Access
+	switch strings.ToLower(s) {
+	case "":
+		if writable {
+			return AccessReadWrite, nil
+		}
+		return AccessRead, nil
+	case "r", "ro", "read":
+		a = AccessRead
+	case "w", "wo", "write":
+		a = AccessWrite
+	case "rw", "read_write", "read-write":
+		a = AccessReadWrite
+	default:
+		return 0, fmt.Errorf("unknown access %q (want r, w or rw)", s)
+	}
+	if a.CanWrite() && !writable {
+		return 0, fmt.Errorf("table of fc %d is read-only", fc)
+	}
+	return a, nil
+}
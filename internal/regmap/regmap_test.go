@@ -0,0 +1,146 @@
+// internal/regmap/regmap_test.go
+package regmap
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+const meterYAML = `# three-phase meter
+points:
+  - name: voltage_l1
+    table: input
+    address: 0
+    type: float32
+    byte_order: CDAB
+    unit: V
+  - name: serial   # 8 characters
+    table: holding
+    address: 0x10
+    type: string
+    length: 4
+  - name: energy
+    fc: 3
+    address: 20
+    type: uint32
+    scale: 0.1
+    unit: "kWh"
+    access: r
+  - name: relay
+    table: coil
+    address: 5
+`
+
+const meterJSON = `{"points": [
+  {"name": "voltage_l1", "table": "input", "address": 0, "type": "float32", "byte_order": "CDAB", "unit": "V"},
+  {"name": "serial", "table": "holding", "address": "0x10", "type": "string", "length": 4},
+  {"name": "energy", "fc": 3, "address": 20, "type": "uint32", "scale": 0.1, "unit": "kWh", "access": "r"},
+  {"name": "relay", "table": "coil", "address": 5}
+]}`
+
+const meterCSV = `name,table,address,type,byte-order,length,scale,unit,access
+voltage_l1,input,0,float32,CDAB,,,V,
+# serial number, 8 characters
+serial,holding,0x10,string,,4,,,
+energy,3,20,uint32,,,0.1,kWh,r
+relay,coil,5,,,,,,
+`
+
+func TestParse_FormatsAgree(t *testing.T) {
+	want := []Point{
+		{Name: "voltage_l1", Function: 4, Address: 0, Length: 2, Type: format.TypeFloat32, Order: format.OrderCDAB, Scaling: format.Scaling{Unit: "V"}, Access: AccessRead},
+		{Name: "serial", Function: 3, Address: 16, Length: 4, Type: format.TypeString, Access: AccessReadWrite},
+		{Name: "energy", Function: 3, Address: 20, Length: 2, Type: format.TypeUint32, Scaling: format.Scaling{Scale: 0.1, Unit: "kWh"}, Access: AccessRead},
+		{Name: "relay", Function: 1, Address: 5, Length: 1, Type: format.TypeUint16, Access: AccessReadWrite},
+	}
+
+	y, err := ParseYAML([]byte(meterYAML))
+	if err != nil {
+		t.Fatalf("yaml: %v", err)
+	}
+	j, err := ParseJSON([]byte(meterJSON))
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	c, err := ParseCSV(strings.NewReader(meterCSV))
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+
+	for name, m := range map[string]*Map{"yaml": y, "json": j, "csv": c} {
+		if !reflect.DeepEqual(m.Points, want) {
+			t.Fatalf("%s: got %+v", name, m.Points)
+		}
+	}
+}
+
+func TestLoad_ByExtension(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "meter.yml")
+	if err := os.WriteFile(path, []byte(meterYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := Load(path)
+	if err != nil || len(m.Points) != 4 {
+		t.Fatalf("got %v, %v", m, err)
+	}
+
+	bad := filepath.Join(dir, "meter.txt")
+	if err := os.WriteFile(bad, []byte(meterYAML), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(bad); err == nil {
+		t.Fatal("expected error for unknown extension")
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"no table":      "- name: a\n  address: 1\n",
+		"unknown field": "- name: a\n  table: holding\n  adress: 1\n",
+		"duplicate":     "- name: a\n  table: holding\n- name: a\n  table: input\n",
+		"string length": "- name: a\n  table: holding\n  type: string\n",
+		"write input":   "- name: a\n  table: input\n  access: rw\n",
+		"typed coil":    "- name: a\n  table: coil\n  type: float32\n",
+		"past 65535":    "- name: a\n  table: holding\n  address: 65535\n  type: uint32\n",
+		"wrong length":  "- name: a\n  table: holding\n  type: uint32\n  length: 3\n",
+		"top-level key": "device: meter\n",
+		"no colon":      "- name a\n",
+		"flow style":    "- {name: a, table: holding}\n",
+		"empty":         "# nothing\n",
+	}
+	for name, doc := range cases {
+		if _, err := ParseYAML([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestMap_Select(t *testing.T) {
+	m, err := ParseYAML([]byte(meterYAML + "  - name: setpoint\n    table: holding\n    address: 30\n    access: w\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := m.Select()
+	if err != nil || len(all) != 4 {
+		t.Fatalf("all readable: got %d points, %v", len(all), err)
+	}
+
+	one, err := m.Select("energy")
+	if err != nil || len(one) != 1 || one[0].Address != 20 {
+		t.Fatalf("got %v, %v", one, err)
+	}
+
+	if _, err := m.Select("nope"); err == nil {
+		t.Fatal("expected error for unknown point")
+	}
+	if _, err := m.Select("setpoint"); err == nil {
+		t.Fatal("expected error for write-only point")
+	}
+}
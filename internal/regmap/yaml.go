@@ -0,0 +1,102 @@
+// internal/regmap/yaml.go
+package regmap
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// ParseYAML reads the subset of YAML a register map needs: an optional
+// "points:" key holding a list of flat "key: value" mappings. Values
+// may be quoted; # starts a comment. Nesting, flow style and anchors
+// are not supported.
+//
+//	points:
+//	  - name: voltage_l1
+//	    table: input
+//	    address: 0
+//	    type: float32
+//	    unit: V
+func ParseYAML(b []byte) (*Map, error) {
+	var (
+		entries []map[string]string
+		cur     map[string]string
+		indent  = -1 // indentation of the current item's keys
+	)
+
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; sc.Scan(); n++ {
+		line := stripComment(sc.Text())
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		text := strings.TrimSpace(line)
+		if strings.HasPrefix(line[depth:], "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed for indentation", n)
+		}
+
+		if text == "-" || strings.HasPrefix(text, "- ") {
+			cur = map[string]string{}
+			entries = append(entries, cur)
+			text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+			indent = depth + 2
+			if text == "" {
+				continue
+			}
+			depth = indent
+		}
+
+		if strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[") {
+			return nil, fmt.Errorf("line %d: flow style is not supported", n)
+		}
+		k, v, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key: value", n)
+		}
+		k, v = fieldKey(k), unquote(strings.TrimSpace(v))
+
+		if cur == nil || depth < indent {
+			if k != "points" || v != "" {
+				return nil, fmt.Errorf("line %d: unexpected top-level key %q", n, k)
+			}
+			cur, indent = nil, -1
+			continue
+		}
+		if _, dup := cur[k]; dup {
+			return nil, fmt.Errorf("line %d: duplicate field %q", n, k)
+		}
+		cur[k] = v
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return newMap(entries)
+}
+
+// stripComment drops a # comment that is not inside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
+
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
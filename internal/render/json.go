@@ -0,0 +1,134 @@
+// internal/render/json.go
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+// Schema names the JSON layout written by JSON and NDJSON. Fields are
+// only ever added under the same schema name.
+const Schema = "rdxbus/v1"
+
+type jsonOutput struct {
+	Schema  string           `json:"schema"`
+	Meta    jsonMeta         `json:"meta"`
+	Message string           `json:"message,omitempty"`
+	Columns []jsonColumn     `json:"columns,omitempty"`
+	Rows    []map[string]any `json:"rows,omitempty"`
+	Error   *jsonError       `json:"error,omitempty"`
+}
+
+type jsonMeta struct {
+	Mode      string    `json:"mode,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Target    string    `json:"target,omitempty"`
+	UnitID    uint8     `json:"unit_id"`
+	Function  uint8     `json:"function,omitempty"`
+	Address   *uint16   `json:"address,omitempty"`
+	Quantity  uint16    `json:"quantity,omitempty"`
+	LatencyMS float64   `json:"latency_ms"`
+	TLS       *jsonTLS  `json:"tls,omitempty"`
+}
+
+type jsonTLS struct {
+	Session     string `json:"session"`
+	PeerSubject string `json:"peer_subject"`
+	PeerRole    string `json:"peer_role,omitempty"`
+}
+
+type jsonColumn struct {
+	Key   string `json:"key"`
+	Title string `json:"title"`
+}
+
+type jsonError struct {
+	Message       string `json:"message"`
+	ExceptionCode uint8  `json:"exception_code,omitempty"`
+}
+
+// JSON writes out as one indented JSON document.
+func JSON(w io.Writer, out output.Output) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(document(out))
+}
+
+// NDJSON writes out as a single line of JSON, so that a stream of
+// outputs (one per poll sample) can be piped into jq or a log shipper.
+func NDJSON(w io.Writer, out output.Output) error {
+	return json.NewEncoder(w).Encode(document(out))
+}
+
+// document maps out to the stable schema. A zero timestamp is
+// replaced by the current time.
+func document(out output.Output) jsonOutput {
+	m := out.Meta
+	doc := jsonOutput{
+		Schema: Schema,
+		Meta: jsonMeta{
+			Mode:      m.Mode,
+			Timestamp: m.Timestamp,
+			Target:    m.Target,
+			UnitID:    m.UnitID,
+			Function:  m.Function,
+			Quantity:  m.Quantity,
+			LatencyMS: float64(m.Latency.Microseconds()) / 1000,
+		},
+		Message: strings.TrimSpace(out.Message),
+	}
+	if doc.Meta.Timestamp.IsZero() {
+		doc.Meta.Timestamp = time.Now()
+	}
+	doc.Meta.Timestamp = doc.Meta.Timestamp.UTC()
+	if m.Quantity > 0 {
+		addr := m.Address
+		doc.Meta.Address = &addr
+	}
+	if m.TLS != "" {
+		doc.Meta.TLS = &jsonTLS{Session: m.TLS, PeerSubject: m.PeerSubject, PeerRole: m.PeerRole}
+	}
+
+	if out.Error != "" {
+		doc.Error = &jsonError{Message: out.Error, ExceptionCode: out.Exception}
+	}
+
+	if t := out.Table; t != nil {
+		doc.Columns = make([]jsonColumn, len(t.Columns))
+		for i, c := range t.Columns {
+			doc.Columns[i] = jsonColumn{Key: c.Key, Title: c.Title}
+		}
+		doc.Rows = make([]map[string]any, len(t.Rows))
+		for i, r := range t.Rows {
+			row := make(map[string]any, len(r.Cells))
+			for k, v := range r.Cells {
+				row[k] = cell(v)
+			}
+			doc.Rows[i] = row
+		}
+	}
+	return doc
+}
+
+// cell maps a NaN or infinite float, which JSON cannot represent, to
+// null, so one such register does not fail the whole document.
+func cell(v any) any {
+	var x float64
+	switch f := v.(type) {
+	case float32:
+		x = float64(f)
+	case float64:
+		x = f
+	default:
+		return v
+	}
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil
+	}
+	return v
+}
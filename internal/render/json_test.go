@@ -0,0 +1,133 @@
+// internal/render/json_test.go
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tamzrod/rdxbus/internal/format"
+	"github.com/tamzrod/rdxbus/internal/output"
+)
+
+func TestNDJSON_OneLinePerOutput(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 2; i++ {
+		if err := NDJSON(&buf, output.Output{Meta: output.Meta{Mode: "read"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), buf.String())
+	}
+	for _, l := range lines {
+		if !json.Valid([]byte(l)) {
+			t.Fatalf("invalid JSON line %q", l)
+		}
+	}
+}
+
+func TestJSON_Schema(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	out := output.Output{
+		Meta: output.Meta{
+			Mode:      "read",
+			Target:    "10.0.0.5:502",
+			UnitID:    1,
+			Function:  3,
+			Address:   0,
+			Quantity:  2,
+			Latency:   1500 * time.Microsecond,
+			Timestamp: at,
+		},
+		Message: "points: 1\n",
+		Table: &output.Table{
+			Columns: []output.Column{{Key: "address", Title: "Address"}, {Key: "scaled", Title: "Scaled"}},
+			Rows: []output.Row{{Cells: map[string]any{
+				"address": 0,
+				"scaled":  format.Engineering{Value: 235.1, Unit: "V"},
+			}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NDJSON(&buf, out); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"schema":"rdxbus/v1",` +
+		`"meta":{"mode":"read","timestamp":"2024-05-01T10:00:00Z","target":"10.0.0.5:502","unit_id":1,"function":3,"address":0,"quantity":2,"latency_ms":1.5},` +
+		`"message":"points: 1",` +
+		`"columns":[{"key":"address","title":"Address"},{"key":"scaled","title":"Scaled"}],` +
+		`"rows":[{"address":0,"scaled":{"value":235.1,"unit":"V"}}]}` + "\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestJSON_NonFiniteCells(t *testing.T) {
+	out := output.Output{
+		Meta: output.Meta{Mode: "read", Function: 3, Quantity: 6},
+		Table: &output.Table{
+			Columns: []output.Column{{Key: "address", Title: "Address"}, {Key: "value", Title: "Value"}},
+			Rows: []output.Row{
+				{Cells: map[string]any{"address": 0, "value": float32(math.NaN())}},
+				{Cells: map[string]any{"address": 2, "value": math.Inf(-1)}},
+				{Cells: map[string]any{"address": 4, "value": 1.5}},
+			},
+		},
+	}
+
+	for name, write := range map[string]func(io.Writer, output.Output) error{"json": JSON, "ndjson": NDJSON} {
+		var buf bytes.Buffer
+		if err := write(&buf, out); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var doc struct{ Rows []map[string]any }
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(doc.Rows) != 3 || doc.Rows[0]["value"] != nil || doc.Rows[1]["value"] != nil || doc.Rows[2]["value"] != 1.5 {
+			t.Fatalf("%s: rows = %v", name, doc.Rows)
+		}
+		if _, ok := doc.Rows[0]["value"]; !ok {
+			t.Fatalf("%s: NaN cell dropped instead of null", name)
+		}
+	}
+}
+
+func TestJSON_Error(t *testing.T) {
+	var buf bytes.Buffer
+	err := NDJSON(&buf, output.Output{
+		Meta:      output.Meta{Mode: "read", Function: 3},
+		Error:     "modbus exception fc=3 code=2",
+		Exception: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		Meta  map[string]any
+		Error struct {
+			Message       string
+			ExceptionCode int `json:"exception_code"`
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Error.Message != "modbus exception fc=3 code=2" || doc.Error.ExceptionCode != 2 {
+		t.Fatalf("error = %+v", doc.Error)
+	}
+	if _, ok := doc.Meta["address"]; ok {
+		t.Fatal("address present without a quantity")
+	}
+	if ts, _ := doc.Meta["timestamp"].(string); ts == "" || ts == "0001-01-01T00:00:00Z" {
+		t.Fatalf("timestamp = %q, want the current time", ts)
+	}
+}
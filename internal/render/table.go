@@ -17,7 +17,11 @@ func Render(w io.Writer, out output.Output) {
 
 	if out.Error != "" {
 		fmt.Fprintf(w, "ERROR: %s\n", out.Error)
-		return
+		if out.Table == nil {
+			return
+		}
+		// A partial failure keeps the rows that tell which part.
+		fmt.Fprintln(w)
 	}
 
 	if out.Table != nil {
@@ -44,6 +48,13 @@ func renderMeta(w io.Writer, m output.Meta) {
 	if m.Latency > 0 {
 		fmt.Fprintf(w, "Latency:  %s\n", m.Latency)
 	}
+	if m.TLS != "" {
+		fmt.Fprintf(w, "TLS:      %s\n", m.TLS)
+		fmt.Fprintf(w, "Peer:     %s\n", m.PeerSubject)
+		if m.PeerRole != "" {
+			fmt.Fprintf(w, "Role:     %s\n", m.PeerRole)
+		}
+	}
 
 	fmt.Fprintln(w)
 }
@@ -61,7 +72,7 @@ func renderTable(w io.Writer, t *output.Table) {
 
 	for _, row := range t.Rows {
 		for _, col := range t.Columns {
-			val := fmt.Sprintf("%v", row.Cells[col.Key])
+			val := cellText(row.Cells[col.Key])
 			if len(val) > widths[col.Key] {
 				widths[col.Key] = len(val)
 			}
@@ -89,9 +100,17 @@ func renderTable(w io.Writer, t *output.Table) {
 			if i > 0 {
 				fmt.Fprint(w, "  ")
 			}
-			val := fmt.Sprintf("%v", row.Cells[col.Key])
+			val := cellText(row.Cells[col.Key])
 			fmt.Fprintf(w, "%-*s", widths[col.Key], val)
 		}
 		fmt.Fprintln(w)
 	}
 }
+
+// cellText formats a cell; a missing cell is blank.
+func cellText(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
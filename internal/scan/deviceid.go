@@ -0,0 +1,78 @@
+// internal/scan/deviceid.go
+package scan
+
+import (
+	"fmt"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+	"github.com/tamzrod/rdxbus/internal/format"
+)
+
+// DeviceIDScan reads all device identification objects of one access
+// level, following "more follows" / next object id until the device
+// reports the last object.
+type DeviceIDScan struct {
+	Base engine.Request
+
+	// Conformity is the level reported by the device.
+	Conformity uint8
+	Objects    []format.DeviceObject
+
+	// Err is the error that ended the scan, if any.
+	Err error
+
+	next uint8
+	done bool
+}
+
+// NewDeviceIDScan reads objects of access code (1–4) starting at objectID.
+func NewDeviceIDScan(base engine.Request, code, objectID uint8) *DeviceIDScan {
+	base.FunctionCode = 43
+	base.DeviceIDCode = code
+	return &DeviceIDScan{
+		Base: base,
+		next: objectID,
+	}
+}
+
+func (s *DeviceIDScan) Next() (engine.Request, bool) {
+	if s.done {
+		return engine.Request{}, false
+	}
+
+	req := s.Base
+	req.ObjectID = s.next
+	return req, true
+}
+
+func (s *DeviceIDScan) Observe(result engine.Result) Decision {
+	s.done = true
+
+	if result.Err != nil {
+		s.Err = result.Err
+		return Stop
+	}
+
+	id, err := format.DecodeDeviceID(result.Raw)
+	if err != nil {
+		s.Err = err
+		return Stop
+	}
+
+	s.Conformity = id.Conformity
+	s.Objects = append(s.Objects, id.Objects...)
+
+	if !id.MoreFollows || s.Base.DeviceIDCode == 4 {
+		return Stop
+	}
+
+	// The next object id must advance, or the device would loop forever.
+	if id.NextObjectID <= s.next {
+		s.Err = fmt.Errorf("device id continuation did not advance: next object %d after %d", id.NextObjectID, s.next)
+		return Stop
+	}
+
+	s.next = id.NextObjectID
+	s.done = false
+	return Continue
+}
@@ -0,0 +1,71 @@
+// internal/scan/deviceid_test.go
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+)
+
+// pagedDeviceID answers basic identification one object per response.
+type pagedDeviceID struct {
+	objects []string
+	asked   []uint8
+}
+
+func (e *pagedDeviceID) Execute(ctx context.Context, req engine.Request) engine.Result {
+	e.asked = append(e.asked, req.ObjectID)
+
+	id := int(req.ObjectID)
+	more, next := byte(0x00), byte(0)
+	if id+1 < len(e.objects) {
+		more, next = 0xFF, byte(id+1)
+	}
+
+	v := e.objects[id]
+	pdu := []byte{43, 14, req.DeviceIDCode, 0x81, more, next, 1, byte(id), byte(len(v))}
+	return engine.Result{Raw: append(pdu, v...)}
+}
+
+func TestDeviceIDScan_FollowsContinuation(t *testing.T) {
+	eng := &pagedDeviceID{objects: []string{"Acme", "PM-100", "v1.2"}}
+	strat := NewDeviceIDScan(engine.Request{UnitID: 1}, 1, 0)
+
+	(&Runner{Engine: eng}).Run(context.Background(), strat)
+
+	if strat.Err != nil {
+		t.Fatalf("unexpected error: %v", strat.Err)
+	}
+	if len(eng.asked) != 3 || eng.asked[1] != 1 || eng.asked[2] != 2 {
+		t.Fatalf("unexpected object ids requested: %v", eng.asked)
+	}
+	if len(strat.Objects) != 3 || strat.Objects[2].Value != "v1.2" {
+		t.Fatalf("unexpected objects: %+v", strat.Objects)
+	}
+	if strat.Conformity != 0x81 {
+		t.Fatalf("unexpected conformity 0x%02X", strat.Conformity)
+	}
+}
+
+// stuckDeviceID always claims more objects follow from object 0.
+type stuckDeviceID struct{ calls int }
+
+func (e *stuckDeviceID) Execute(ctx context.Context, req engine.Request) engine.Result {
+	e.calls++
+	return engine.Result{Raw: []byte{43, 14, 1, 0x01, 0xFF, 0, 1, 0, 1, 'A'}}
+}
+
+func TestDeviceIDScan_StopsWhenNextDoesNotAdvance(t *testing.T) {
+	eng := &stuckDeviceID{}
+	strat := NewDeviceIDScan(engine.Request{UnitID: 1}, 1, 0)
+
+	(&Runner{Engine: eng}).Run(context.Background(), strat)
+
+	if strat.Err == nil {
+		t.Fatalf("expected error for a non-advancing continuation")
+	}
+	if eng.calls != 1 {
+		t.Fatalf("expected 1 request, got %d", eng.calls)
+	}
+}
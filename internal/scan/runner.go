@@ -14,6 +14,10 @@ type Runner struct {
 
 func (r *Runner) Run(ctx context.Context, strat Strategy) {
 	for {
+		if ctx.Err() != nil {
+			return
+		}
+
 		req, ok := strat.Next()
 		if !ok {
 			return
@@ -0,0 +1,34 @@
+// internal/scan/runner_test.go
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+)
+
+type cancelingEngine struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (e *cancelingEngine) Execute(ctx context.Context, req engine.Request) engine.Result {
+	e.calls++
+	e.cancel() // e.g. Ctrl+C during the first request
+	return engine.Result{Err: &engine.CanceledError{Err: ctx.Err()}}
+}
+
+func TestRunner_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eng := &cancelingEngine{cancel: cancel}
+	strat := NewUnitIDScan(engine.Request{FunctionCode: 3, Quantity: 1}, 1, 247, 1)
+
+	(&Runner{Engine: eng}).Run(ctx, strat)
+
+	if eng.calls != 1 {
+		t.Fatalf("expected runner to stop after cancellation, got %d calls", eng.calls)
+	}
+}
@@ -11,6 +11,8 @@ type UnitIDScan struct {
 	Step  uint8
 
 	current uint8
+	last    uint8
+	found   bool
 	done    bool
 }
 
@@ -32,14 +34,21 @@ func (s *UnitIDScan) Next() (engine.Request, bool) {
 	req := s.Base
 	req.UnitID = s.current
 
+	s.last = s.current
 	s.current += s.Step
 	return req, true
 }
 
 func (s *UnitIDScan) Observe(result engine.Result) Decision {
 	if result.Err == nil {
+		s.found = true
 		s.done = true
 		return Stop
 	}
 	return Continue
 }
+
+// Found returns the unit id that answered, if any.
+func (s *UnitIDScan) Found() (uint8, bool) {
+	return s.last, s.found
+}
@@ -7,17 +7,23 @@ type Counters struct {
 	OK         uint64
 	Exceptions uint64
 	OtherErrs  uint64
+
+	// Canceled counts requests abandoned by the caller. They are not
+	// device errors and are not included in Requests.
+	Canceled uint64
 }
 
 func (c *Counters) IncRequests()   { atomic.AddUint64(&c.Requests, 1) }
 func (c *Counters) IncOK()         { atomic.AddUint64(&c.OK, 1) }
 func (c *Counters) IncExceptions() { atomic.AddUint64(&c.Exceptions, 1) }
 func (c *Counters) IncOtherErrs()  { atomic.AddUint64(&c.OtherErrs, 1) }
+func (c *Counters) IncCanceled()   { atomic.AddUint64(&c.Canceled, 1) }
 
-func (c *Counters) Snapshot() (req, ok, ex, other uint64) {
+func (c *Counters) Snapshot() (req, ok, ex, other, canceled uint64) {
 	req = atomic.LoadUint64(&c.Requests)
 	ok = atomic.LoadUint64(&c.OK)
 	ex = atomic.LoadUint64(&c.Exceptions)
 	other = atomic.LoadUint64(&c.OtherErrs)
+	canceled = atomic.LoadUint64(&c.Canceled)
 	return
 }
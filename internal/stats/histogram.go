@@ -2,10 +2,16 @@ package stats
 
 import (
 	"math/bits"
+	"sync"
 	"time"
 )
 
+// Histogram records latencies in power-of-two nanosecond buckets. It
+// is safe for concurrent use, so a metrics scrape can snapshot it while
+// results are recorded.
 type Histogram struct {
+	mu sync.Mutex
+
 	buckets [64]uint64
 
 	minNS uint64
@@ -24,6 +30,9 @@ func (h *Histogram) Record(d time.Duration) {
 		ns = 1
 	}
 
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if h.count == 0 {
 		h.minNS = ns
 		h.maxNS = ns
@@ -56,6 +65,8 @@ type HistSnapshot struct {
 }
 
 func (h *Histogram) Snapshot() HistSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return HistSnapshot{
 		Buckets: h.buckets,
 		MinNS:   h.minNS,
@@ -12,6 +12,7 @@ type Report struct {
 	OK         uint64
 	Exceptions uint64
 	OtherErrs  uint64
+	Canceled   uint64
 
 	MinNS uint64
 	AvgNS uint64
@@ -23,7 +24,7 @@ type Report struct {
 }
 
 func BuildReport(duration time.Duration, c *Counters, h HistSnapshot) Report {
-	req, ok, ex, other := c.Snapshot()
+	req, ok, ex, other, canceled := c.Snapshot()
 
 	rps := 0.0
 	if duration > 0 {
@@ -37,6 +38,7 @@ func BuildReport(duration time.Duration, c *Counters, h HistSnapshot) Report {
 		OK:         ok,
 		Exceptions: ex,
 		OtherErrs:  other,
+		Canceled:   canceled,
 
 		MinNS: h.MinNS,
 		AvgNS: h.AvgNS(),
@@ -50,8 +52,8 @@ func BuildReport(duration time.Duration, c *Counters, h HistSnapshot) Report {
 
 func (r Report) String() string {
 	return fmt.Sprintf(
-		"Requests:   %d\nOK:         %d\nExceptions: %d\nOtherErrs:  %d\n\nLatency (ms):\n  min  %.3f\n  avg  %.3f\n  p95  %.3f\n  p99  %.3f\n  max  %.3f\n\nThroughput:\n  %.1f req/s\n",
-		r.Requests, r.OK, r.Exceptions, r.OtherErrs,
+		"Requests:   %d\nOK:         %d\nExceptions: %d\nOtherErrs:  %d\nCanceled:   %d\n\nLatency (ms):\n  min  %.3f\n  avg  %.3f\n  p95  %.3f\n  p99  %.3f\n  max  %.3f\n\nThroughput:\n  %.1f req/s\n",
+		r.Requests, r.OK, r.Exceptions, r.OtherErrs, r.Canceled,
 		nsToMS(r.MinNS),
 		nsToMS(r.AvgNS),
 		nsToMS(r.P95NS),
@@ -3,6 +3,7 @@ package worker
 
 import (
 	"context"
+	"sync"
 
 	"github.com/tamzrod/rdxbus/internal/engine"
 )
@@ -23,3 +24,49 @@ func Execute(
 		EngineResult: eng.Execute(ctx, req),
 	}
 }
+
+// Run executes one request per tick until ticks is closed.
+// Ticks that arrive after ctx is done are drained without
+// executing, so the scheduler is never left blocked on a send.
+func Run(
+	ctx context.Context,
+	eng engine.Engine,
+	req engine.Request,
+	ticks <-chan struct{},
+	out chan<- Result,
+) {
+	for range ticks {
+		if ctx.Err() != nil {
+			continue
+		}
+		out <- Execute(ctx, eng, req)
+	}
+}
+
+// Spawn starts n workers sharing the same tick channel.
+// The returned channel is closed once every worker has exited.
+func Spawn(
+	ctx context.Context,
+	n int,
+	eng engine.Engine,
+	req engine.Request,
+	ticks <-chan struct{},
+) <-chan Result {
+	out := make(chan Result, n)
+
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			Run(ctx, eng, req, ticks, out)
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,68 @@
+// internal/worker/worker_test.go
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/tamzrod/rdxbus/internal/engine"
+)
+
+type countingEngine struct {
+	calls int64
+}
+
+func (e *countingEngine) Execute(ctx context.Context, req engine.Request) engine.Result {
+	atomic.AddInt64(&e.calls, 1)
+	return engine.Result{UnitID: req.UnitID}
+}
+
+func TestSpawn_OneExecutionPerTick(t *testing.T) {
+	eng := &countingEngine{}
+	ticks := make(chan struct{})
+
+	results := Spawn(context.Background(), 4, eng, engine.Request{UnitID: 7}, ticks)
+
+	go func() {
+		for i := 0; i < 25; i++ {
+			ticks <- struct{}{}
+		}
+		close(ticks)
+	}()
+
+	count := 0
+	for res := range results {
+		if res.EngineResult.UnitID != 7 {
+			t.Fatalf("unexpected result: %+v", res.EngineResult)
+		}
+		count++
+	}
+
+	if count != 25 || atomic.LoadInt64(&eng.calls) != 25 {
+		t.Fatalf("expected 25 executions, got results=%d calls=%d", count, eng.calls)
+	}
+}
+
+func TestSpawn_DrainsTicksAfterCancel(t *testing.T) {
+	eng := &countingEngine{}
+	ticks := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := Spawn(ctx, 2, eng, engine.Request{}, ticks)
+
+	// Sends must not block even though no work is executed.
+	for i := 0; i < 10; i++ {
+		ticks <- struct{}{}
+	}
+	close(ticks)
+
+	for range results {
+		t.Fatalf("no results expected after cancel")
+	}
+	if atomic.LoadInt64(&eng.calls) != 0 {
+		t.Fatalf("expected no executions, got %d", eng.calls)
+	}
+}